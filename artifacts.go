@@ -1,7 +1,6 @@
 package youtube
 
 import (
-	"log/slog"
 	"os"
 	"path/filepath"
 )
@@ -9,18 +8,18 @@ import (
 // destination for artifacts, used by integration tests
 var artifactsFolder = os.Getenv("ARTIFACTS")
 
-func writeArtifact(name string, content []byte) {
+func (c *Client) writeArtifact(name string, content []byte) {
 	// Ensure folder exists
 	err := os.MkdirAll(artifactsFolder, os.ModePerm)
 	if err != nil {
-		slog.Error("unable to create artifacts folder", "path", artifactsFolder, "error", err)
+		c.logger().Error("unable to create artifacts folder", "path", artifactsFolder, "error", err)
 		return
 	}
 
 	path := filepath.Join(artifactsFolder, name)
 	err = os.WriteFile(path, content, 0600)
 
-	log := slog.With("path", path)
+	log := c.logger().With("path", path)
 	if err != nil {
 		log.Error("unable to write artifact", "error", err)
 	} else {
@@ -0,0 +1,117 @@
+package youtube
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// CaptionSegment is a single line of a caption track.
+type CaptionSegment struct {
+	Start    time.Duration
+	Duration time.Duration
+	Text     string
+}
+
+type Captions []CaptionSegment
+
+// SRT formats the captions as SubRip subtitles.
+func (cs Captions) SRT() string {
+	var sb strings.Builder
+
+	for i, segment := range cs {
+		fmt.Fprintf(&sb, "%d\n%s --> %s\n%s\n\n",
+			i+1,
+			formatSRTTime(segment.Start),
+			formatSRTTime(segment.Start+segment.Duration),
+			segment.Text,
+		)
+	}
+
+	return sb.String()
+}
+
+func formatSRTTime(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d:%02d,%03d",
+		int(d.Hours()),
+		int(d.Minutes())%60,
+		int(d.Seconds())%60,
+		d.Milliseconds()%1000,
+	)
+}
+
+// GetCaptions fetches the segments of a caption track.
+func (c *Client) GetCaptions(track *CaptionTrack) (Captions, error) {
+	return c.GetCaptionsContext(context.Background(), track)
+}
+
+// GetCaptionsContext fetches the segments of a caption track with a context.
+func (c *Client) GetCaptionsContext(ctx context.Context, track *CaptionTrack) (Captions, error) {
+	c.assureClient()
+
+	if track == nil || track.BaseURL == "" {
+		return nil, fmt.Errorf("no caption track provided")
+	}
+
+	body, err := c.httpGetBodyBytes(ctx, track.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCaptions(body)
+}
+
+// timedText is the XML structure as returned by the timedtext API.
+// Depending on the requested format, it contains either text or p elements.
+type timedText struct {
+	Texts []struct {
+		Start float64 `xml:"start,attr"`
+		Dur   float64 `xml:"dur,attr"`
+		Text  string  `xml:",chardata"`
+	} `xml:"text"`
+	Paragraphs []struct {
+		Start int      `xml:"t,attr"`
+		Dur   int      `xml:"d,attr"`
+		Text  string   `xml:",chardata"`
+		Spans []string `xml:"s"`
+	} `xml:"body>p"`
+}
+
+func parseCaptions(body []byte) (Captions, error) {
+	var tt timedText
+	if err := xml.Unmarshal(body, &tt); err != nil {
+		return nil, fmt.Errorf("unable to parse timedtext XML: %w", err)
+	}
+
+	captions := make(Captions, 0, len(tt.Texts)+len(tt.Paragraphs))
+
+	for _, text := range tt.Texts {
+		captions = append(captions, CaptionSegment{
+			Start:    time.Duration(text.Start * float64(time.Second)),
+			Duration: time.Duration(text.Dur * float64(time.Second)),
+			Text:     html.UnescapeString(text.Text),
+		})
+	}
+
+	for _, p := range tt.Paragraphs {
+		text := p.Text
+		if len(p.Spans) > 0 {
+			text = strings.Join(p.Spans, "")
+		}
+
+		if text = strings.TrimSpace(text); text == "" {
+			continue
+		}
+
+		captions = append(captions, CaptionSegment{
+			Start:    time.Duration(p.Start) * time.Millisecond,
+			Duration: time.Duration(p.Dur) * time.Millisecond,
+			Text:     html.UnescapeString(text),
+		})
+	}
+
+	return captions, nil
+}
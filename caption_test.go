@@ -0,0 +1,54 @@
+package youtube
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCaptions(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{
+			name: "transcript format",
+			body: `<?xml version="1.0" encoding="utf-8" ?><transcript>` +
+				`<text start="0.5" dur="1.25">Hello &amp;#39;world&amp;#39;</text>` +
+				`<text start="61" dur="2">second line</text>` +
+				`</transcript>`,
+		},
+		{
+			name: "timedtext format 3",
+			body: `<?xml version="1.0" encoding="utf-8" ?><timedtext format="3"><body>` +
+				`<p t="500" d="1250">Hello &#39;world&#39;</p>` +
+				`<p t="1000" d="0"> </p>` +
+				`<p t="61000" d="2000"><s>second</s><s> line</s></p>` +
+				`</body></timedtext>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captions, err := parseCaptions([]byte(tt.body))
+			require.NoError(t, err)
+
+			assert.Equal(t, Captions{
+				{Start: 500 * time.Millisecond, Duration: 1250 * time.Millisecond, Text: "Hello 'world'"},
+				{Start: 61 * time.Second, Duration: 2 * time.Second, Text: "second line"},
+			}, captions)
+		})
+	}
+}
+
+func TestCaptions_SRT(t *testing.T) {
+	captions := Captions{
+		{Start: 500 * time.Millisecond, Duration: 1250 * time.Millisecond, Text: "Hello"},
+		{Start: time.Hour + 61*time.Second, Duration: 2 * time.Second, Text: "World"},
+	}
+
+	assert.Equal(t, "1\n00:00:00,500 --> 00:00:01,750\nHello\n\n"+
+		"2\n01:01:01,000 --> 01:01:03,000\nWorld\n\n", captions.SRT())
+}
@@ -0,0 +1,119 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrNoChapters = errors.New("video has no chapters")
+
+// chapterPattern matches description lines like "0:00 Intro", "(1:02:03) - Outro" or "12:34 | Topic"
+var chapterPattern = regexp.MustCompile(`^[(\[]?((?:\d{1,2}:)?\d{1,2}:\d{2})[)\]]?\s*[-–—:|]?\s*(.+)$`)
+
+// minChapters is the minimum number of chapters YouTube requires to show them
+const minChapters = 3
+
+// Chapter is a section of the video as listed in its description
+type Chapter struct {
+	Start time.Duration
+	End   time.Duration // start of the next chapter or the end of the video, 0 if unknown
+	Title string
+}
+
+type Chapters []Chapter
+
+// parseChapters parses the chapter markers of a description.
+// Like YouTube, it requires the first chapter to start at 0:00, at least three chapters and ascending timestamps.
+// Only the first block of timestamp lines starting at 0:00 is used, it ends at the first other line
+// or descending timestamp, so later references like "see 0:42" don't discard the chapters.
+func parseChapters(description string, duration time.Duration) Chapters {
+	var chapters Chapters
+
+	for _, line := range strings.Split(description, "\n") {
+		match := chapterPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			if len(chapters) > 0 {
+				break
+			}
+			continue
+		}
+
+		start := parseChapterTimestamp(match[1])
+		if len(chapters) == 0 && start != 0 {
+			continue
+		}
+		if len(chapters) > 0 && start <= chapters[len(chapters)-1].Start {
+			break
+		}
+
+		chapters = append(chapters, Chapter{Start: start, Title: strings.TrimSpace(match[2])})
+	}
+
+	if len(chapters) < minChapters {
+		return nil
+	}
+
+	for i := range chapters {
+		if i+1 < len(chapters) {
+			chapters[i].End = chapters[i+1].Start
+		} else if duration > chapters[i].Start {
+			chapters[i].End = duration
+		}
+	}
+
+	return chapters
+}
+
+// parseChapterTimestamp parses a timestamp like 1:02:03 or 12:34
+func parseChapterTimestamp(s string) time.Duration {
+	var d time.Duration
+	for _, part := range strings.Split(s, ":") {
+		value, _ := strconv.Atoi(part)
+		d = d*60 + time.Duration(value)
+	}
+
+	return d * time.Second
+}
+
+// WebVTT formats the chapters as a WebVTT chapters file.
+func (cs Chapters) WebVTT() string {
+	var sb strings.Builder
+	sb.WriteString("WEBVTT\n\n")
+
+	for i, chapter := range cs {
+		fmt.Fprintf(&sb, "%d\n%s --> %s\n%s\n\n",
+			i+1,
+			formatVTTTime(chapter.Start),
+			formatVTTTime(chapter.End),
+			chapter.Title,
+		)
+	}
+
+	return sb.String()
+}
+
+// FFMetadata formats the chapters as an ffmpeg metadata file, which can be muxed with -map_metadata.
+func (cs Chapters) FFMetadata() string {
+	var sb strings.Builder
+	sb.WriteString(";FFMETADATA1\n")
+
+	escaper := strings.NewReplacer(`\`, `\\`, "=", `\=`, ";", `\;`, "#", `\#`, "\n", `\`+"\n")
+
+	for _, chapter := range cs {
+		fmt.Fprintf(&sb, "\n[CHAPTER]\nTIMEBASE=1/1000\nSTART=%d\nEND=%d\ntitle=%s\n",
+			chapter.Start.Milliseconds(),
+			chapter.End.Milliseconds(),
+			escaper.Replace(chapter.Title),
+		)
+	}
+
+	return sb.String()
+}
+
+func formatVTTTime(d time.Duration) string {
+	return strings.Replace(formatSRTTime(d), ",", ".", 1)
+}
@@ -0,0 +1,82 @@
+package youtube
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChapters(t *testing.T) {
+	description := "A lecture about Go.\n\n" +
+		"0:00 Intro\n" +
+		"(1:30) - Goroutines\n" +
+		"12:05 | Channels\n" +
+		"1:02:03 Q&A\n\n" +
+		"Follow us on 10:00 every day"
+
+	chapters := parseChapters(description, 70*time.Minute)
+
+	assert.Equal(t, Chapters{
+		{Start: 0, End: 90 * time.Second, Title: "Intro"},
+		{Start: 90 * time.Second, End: 12*time.Minute + 5*time.Second, Title: "Goroutines"},
+		{Start: 12*time.Minute + 5*time.Second, End: time.Hour + 2*time.Minute + 3*time.Second, Title: "Channels"},
+		{Start: time.Hour + 2*time.Minute + 3*time.Second, End: 70 * time.Minute, Title: "Q&A"},
+	}, chapters)
+}
+
+func TestParseChapters_FirstBlock(t *testing.T) {
+	description := "Jump to 2:00 for the demo.\n" +
+		"0:00 Intro\n" +
+		"1:00 Setup\n" +
+		"2:00 Demo\n" +
+		"0:42 is where it breaks\n" +
+		"3:00 Outro\n\n" +
+		"0:00 Another list\n" +
+		"see 0:42"
+
+	chapters := parseChapters(description, 4*time.Minute)
+
+	assert.Equal(t, Chapters{
+		{Start: 0, End: time.Minute, Title: "Intro"},
+		{Start: time.Minute, End: 2 * time.Minute, Title: "Setup"},
+		{Start: 2 * time.Minute, End: 4 * time.Minute, Title: "Demo"},
+	}, chapters)
+
+	// a reference below the list is ignored
+	chapters = parseChapters("0:00 Intro\n1:00 Setup\n2:00 Demo\n\nThe bug is at\n0:42 Setup", 0)
+	assert.Len(t, chapters, 3)
+}
+
+func TestParseChapters_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+	}{
+		{"empty", ""},
+		{"not starting at zero", "0:10 Intro\n1:00 Middle\n2:00 End"},
+		{"too few", "0:00 Intro\n1:00 End"},
+		{"not ascending", "0:00 Intro\n2:00 Middle\n1:00 End"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Empty(t, parseChapters(tt.description, time.Hour))
+		})
+	}
+}
+
+func TestChapters_Export(t *testing.T) {
+	chapters := Chapters{
+		{Start: 0, End: 90 * time.Second, Title: "Intro"},
+		{Start: 90 * time.Second, End: time.Hour, Title: "Q=A"},
+	}
+
+	assert.Equal(t, "WEBVTT\n\n"+
+		"1\n00:00:00.000 --> 00:01:30.000\nIntro\n\n"+
+		"2\n00:01:30.000 --> 01:00:00.000\nQ=A\n\n", chapters.WebVTT())
+
+	assert.Equal(t, ";FFMETADATA1\n"+
+		"\n[CHAPTER]\nTIMEBASE=1/1000\nSTART=0\nEND=90000\ntitle=Intro\n"+
+		"\n[CHAPTER]\nTIMEBASE=1/1000\nSTART=90000\nEND=3600000\ntitle=Q\\=A\n", chapters.FFMetadata())
+}
@@ -11,7 +11,10 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"log/slog"
 )
@@ -34,7 +37,9 @@ var DefaultClient = AndroidClient
 // Client offers methods to download video metadata and video streams.
 type Client struct {
 	// HTTPClient can be used to set a custom HTTP client.
-	// If not set, http.DefaultClient will be used
+	// If not set, http.DefaultClient will be used.
+	// The cookies of its Jar are sent with every request including the player API,
+	// e.g. the cookies of a signed-in session to access members-only videos.
 	HTTPClient *http.Client
 
 	// MaxRoutines to use when downloading a video.
@@ -43,14 +48,70 @@ type Client struct {
 	// ChunkSize to use when downloading videos in chunks. Default is Size10Mb.
 	ChunkSize int64
 
-	// playerCache caches the JavaScript code of a player response
-	playerCache playerCache
+	// MaxRetries of requests failing with network errors, 429 or 5xx status codes. Default is 0.
+	MaxRetries int
+
+	// RetryBackoff is the delay before the first retry, doubled on every attempt up to 30 seconds. Default is 1 second.
+	RetryBackoff time.Duration
+
+	// Country is the ISO 3166 country code of requests, e.g. "DE". Default is "US".
+	// Together with a proxy in that country, it gives access to region-restricted videos.
+	Country string
+
+	// Language is the language code of requests, e.g. "de". Default is "en".
+	// Player requests always use "en", as errors like ErrVideoRemoved are detected by the English reason.
+	Language string
+
+	// BaseURL of the YouTube website and API, e.g. a mirror or a test server. Default is https://www.youtube.com.
+	BaseURL string
+
+	// UserAgent overrides the user agent of the innertube client on all requests.
+	UserAgent string
+
+	// PoToken is an optional proof of origin token, which YouTube requires from some clients
+	// to return playable stream URLs. It can't be generated by this package, but it can be
+	// taken from a browser session. It is sent with player requests and appended to stream URLs.
+	PoToken string
+
+	// Header optionally adds headers to all requests, e.g. Referer or X-YouTube-Client-Name.
+	// They replace the default headers of the same name. Setting Accept-Encoding disables
+	// the transparent decompression of responses.
+	Header http.Header
+
+	// Logger optionally sets the logger of this client, e.g. with attributes of the caller
+	// or a handler of another logging library. Default is the global Logger.
+	Logger *slog.Logger
+
+	// ProxyProvider optionally returns the proxy URL for each video, e.g. to rotate http or socks5 proxies.
+	// It is called once by GetVideo, and the streams of the video are requested through the same proxy,
+	// as stream URLs only work from the IP which fetched them. An empty URL uses HTTPClient as is.
+	// Every proxy gets its own copy of the transport of HTTPClient, which has to be an *http.Transport,
+	// kept for the lifetime of the process. Connections can't be shared between proxies, so rotating
+	// over many proxies costs more TCP and TLS handshakes and keeps more idle connections open.
+	ProxyProvider func() string
 
 	client *clientInfo
 
 	consentID string
 }
 
+func (c *Client) baseURL() string {
+	if c.BaseURL != "" {
+		return strings.TrimSuffix(c.BaseURL, "/")
+	}
+
+	return "https://www.youtube.com"
+}
+
+// logger returns the logger of the client or the global Logger
+func (c *Client) logger() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+
+	return Logger
+}
+
 func (c *Client) assureClient() {
 	if c.client == nil {
 		c.client = &DefaultClient
@@ -72,9 +133,162 @@ func (c *Client) GetVideoContext(ctx context.Context, url string) (*Video, error
 	return c.videoFromID(ctx, id)
 }
 
+// GetVideos fetches the metadata of several videos concurrently, see GetVideosContext
+func (c *Client) GetVideos(urls []string) ([]*Video, []error) {
+	return c.GetVideosContext(context.Background(), urls)
+}
+
+// GetVideosContext fetches the metadata of several videos with up to MaxRoutines concurrent requests.
+// The videos and errors have the same index as their URL or ID, a failing video doesn't abort the others.
+func (c *Client) GetVideosContext(ctx context.Context, urls []string) ([]*Video, []error) {
+	c.assureClient()
+
+	videos := make([]*Video, len(urls))
+	errs := make([]error, len(urls))
+
+	var wg sync.WaitGroup
+	indexes := make(chan int)
+
+	for i := 0; i < c.getMaxRoutines(len(urls)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for index := range indexes {
+				// a copy per video, as fetching a video may switch the innertube client
+				client := *c
+				videos[index], errs[index] = client.GetVideoContext(ctx, urls[index])
+			}
+		}()
+	}
+
+	for i := range urls {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return videos, errs
+}
+
+// EstimatePlaylistSize estimates the number of bytes to download the videos in the given quality, see EstimatePlaylistSizeContext
+func (c *Client) EstimatePlaylistSize(urls []string, quality string) (int64, error) {
+	return c.EstimatePlaylistSizeContext(context.Background(), urls, quality)
+}
+
+// EstimatePlaylistSizeContext estimates the number of bytes to download the videos in the given quality,
+// e.g. "medium" or "720p", using the best matching format of each video. Formats without a content length
+// are estimated from their bitrate. Videos which fail or lack the quality are skipped and returned as errors.
+func (c *Client) EstimatePlaylistSizeContext(ctx context.Context, urls []string, quality string) (int64, error) {
+	videos, errs := c.GetVideosContext(ctx, urls)
+
+	var total int64
+	for i, video := range videos {
+		if errs[i] != nil {
+			continue
+		}
+
+		formats := video.Formats.Quality(quality)
+		if len(formats) == 0 {
+			errs[i] = fmt.Errorf("no format with quality %s for %s", quality, video.ID)
+			continue
+		}
+		formats.Sort()
+
+		if formats[0].ContentLength > 0 {
+			total += formats[0].ContentLength
+		} else {
+			total += formats[0].ByteOffset(video.Duration)
+		}
+	}
+
+	return total, errors.Join(errs...)
+}
+
+// IsAvailable checks whether a video can be played, see IsAvailableContext
+func (c *Client) IsAvailable(url string) (bool, error) {
+	return c.IsAvailableContext(context.Background(), url)
+}
+
+// IsAvailableContext checks whether a video can be played with a single request, without parsing its formats
+// or fetching the player, e.g. for link checkers. If not, the error is the reason like ErrVideoPrivate.
+// Age-restricted videos report ErrLoginRequired, although GetVideo may be able to bypass it.
+// If the check itself failed, e.g. on network errors, the error wraps ErrAvailabilityCheckFailed
+// and the result says nothing about the video.
+// The request goes through the ProxyProvider like GetVideo, as the availability may depend on the region.
+func (c *Client) IsAvailableContext(ctx context.Context, url string) (bool, error) {
+	id, err := ExtractVideoID(url)
+	if err != nil {
+		return false, fmt.Errorf("%w: extractVideoID failed: %w", ErrAvailabilityCheckFailed, err)
+	}
+
+	c.assureClient()
+
+	client, err := c.withProxy()
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrAvailabilityCheckFailed, err)
+	}
+
+	// the signature timestamp of web clients is only needed for stream URLs
+	body, err := client.httpPostBodyBytes(ctx, client.baseURL()+"/youtubei/v1/player?key="+client.client.key, client.playerRequest(id))
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrAvailabilityCheckFailed, err)
+	}
+
+	var prData playerResponseData
+	if err := json.Unmarshal(body, &prData); err != nil {
+		return false, fmt.Errorf("%w: unable to parse player response JSON: %w", ErrAvailabilityCheckFailed, err)
+	}
+
+	v := Video{ID: id}
+	if err := v.isVideoFromPageDownloadable(prData); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 func (c *Client) videoFromID(ctx context.Context, id string) (*Video, error) {
 	c.assureClient()
 
+	client, err := c.withProxy()
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := client.fetchVideo(ctx, id)
+	c.client = client.client
+
+	if v != nil {
+		v.client = client.client
+		if client != c {
+			v.httpClient = client.HTTPClient
+		}
+	}
+
+	return v, err
+}
+
+// forVideo returns a client using the innertube client and the proxy which fetched the video,
+// e.g. to unthrottle the streams of a video fetched by the EmbeddedClient
+func (c *Client) forVideo(video *Video) *Client {
+	if video == nil || (video.client == nil || video.client == c.client) && video.httpClient == nil {
+		return c
+	}
+
+	client := *c
+	if video.client != nil {
+		client.client = video.client
+	}
+	if video.httpClient != nil {
+		client.HTTPClient = video.httpClient
+	}
+	return &client
+}
+
+func (c *Client) fetchVideo(ctx context.Context, id string) (*Video, error) {
+	c.assureClient()
+
 	body, err := c.videoDataByInnertube(ctx, id)
 	if err != nil {
 		return nil, err
@@ -89,10 +303,24 @@ func (c *Client) videoFromID(ctx context.Context, id string) (*Video, error) {
 		return &v, nil
 	}
 
+	// If the player API returned no streams, try the player response of the video page
+	if errors.Is(err, ErrNoFormats) {
+		html, errPage := c.httpGetBodyBytes(ctx, c.baseURL()+"/watch?v="+id+"&bpctr=9999999999&has_verified=1")
+		if errPage == nil {
+			page := Video{ID: id}
+			if errPage = page.parseVideoPage(html); errPage == nil {
+				return &page, nil
+			}
+		}
+
+		c.logger().Debug("Unable to get formats from the video page", "id", id, "error", errPage)
+		return &v, err
+	}
+
 	// If the uploader has disabled embedding the video on other sites, parse video page
 	if errors.Is(err, ErrNotPlayableInEmbed) {
 		// additional parameters are required to access clips with sensitiv content
-		html, err := c.httpGetBodyBytes(ctx, "https://www.youtube.com/watch?v="+id+"&bpctr=9999999999&has_verified=1")
+		html, err := c.httpGetBodyBytes(ctx, c.baseURL()+"/watch?v="+id+"&bpctr=9999999999&has_verified=1")
 		if err != nil {
 			return nil, err
 		}
@@ -132,6 +360,7 @@ type innertubeRequest struct {
 	Continuation    string            `json:"continuation,omitempty"`
 	Context         inntertubeContext `json:"context"`
 	PlaybackContext *playbackContext  `json:"playbackContext,omitempty"`
+	Integrity       *integrity        `json:"serviceIntegrityDimensions,omitempty"`
 	ContentCheckOK  bool              `json:"contentCheckOk,omitempty"`
 	RacyCheckOk     bool              `json:"racyCheckOk,omitempty"`
 	Params          string            `json:"params"`
@@ -142,8 +371,12 @@ type playbackContext struct {
 }
 
 type contentPlaybackContext struct {
-	// SignatureTimestamp string `json:"signatureTimestamp"`
-	HTML5Preference string `json:"html5Preference"`
+	SignatureTimestamp int    `json:"signatureTimestamp,omitempty"`
+	HTML5Preference    string `json:"html5Preference"`
+}
+
+type integrity struct {
+	PoToken string `json:"poToken"`
 }
 
 type inntertubeContext struct {
@@ -198,48 +431,82 @@ var (
 )
 
 func (c *Client) videoDataByInnertube(ctx context.Context, id string) ([]byte, error) {
+	data := c.playerRequest(id)
+
+	// the signatures of web clients only match the player of the same timestamp
+	if c.client.androidVersion == 0 {
+		sts, err := c.getSignatureTimestamp(ctx, id)
+		if err != nil {
+			c.logger().Debug("Unable to get the signature timestamp", "error", err)
+		}
+		data.PlaybackContext.ContentPlaybackContext.SignatureTimestamp = sts
+	}
+
+	return c.httpPostBodyBytes(ctx, c.baseURL()+"/youtubei/v1/player?key="+c.client.key, data)
+}
+
+// playerRequest returns the request of the player API without the signature timestamp of web clients
+func (c *Client) playerRequest(id string) innertubeRequest {
+	context := c.prepareInnertubeContext()
+
+	// the reason of the playability status is localized, but only matched in English
+	context.Client.HL = "en"
+
 	data := innertubeRequest{
 		VideoID:        id,
-		Context:        prepareInnertubeContext(*c.client),
+		Context:        context,
 		ContentCheckOK: true,
 		RacyCheckOk:    true,
 		Params:         playerParams,
 		PlaybackContext: &playbackContext{
 			ContentPlaybackContext: contentPlaybackContext{
-				// SignatureTimestamp: sts,
 				HTML5Preference: "HTML5_PREF_WANTS",
 			},
 		},
 	}
 
-	return c.httpPostBodyBytes(ctx, "https://www.youtube.com/youtubei/v1/player?key="+c.client.key, data)
+	if c.PoToken != "" {
+		data.Integrity = &integrity{PoToken: c.PoToken}
+	}
+
+	return data
 }
 
 func (c *Client) transcriptDataByInnertube(ctx context.Context, id string, lang string) ([]byte, error) {
 	data := innertubeRequest{
-		Context: prepareInnertubeContext(*c.client),
+		Context: c.prepareInnertubeContext(),
 		Params:  transcriptVideoID(id, lang),
 	}
 
-	return c.httpPostBodyBytes(ctx, "https://www.youtube.com/youtubei/v1/get_transcript?key="+c.client.key, data)
+	return c.httpPostBodyBytes(ctx, c.baseURL()+"/youtubei/v1/get_transcript?key="+c.client.key, data)
 }
 
-func prepareInnertubeContext(clientInfo clientInfo) inntertubeContext {
+func (c *Client) prepareInnertubeContext() inntertubeContext {
+	language := c.Language
+	if language == "" {
+		language = "en"
+	}
+
+	country := c.Country
+	if country == "" {
+		country = "US"
+	}
+
 	return inntertubeContext{
 		Client: innertubeClient{
-			HL:                "en",
-			GL:                "US",
+			HL:                language,
+			GL:                country,
 			TimeZone:          "UTC",
-			ClientName:        clientInfo.name,
-			ClientVersion:     clientInfo.version,
-			AndroidSDKVersion: clientInfo.androidVersion,
-			UserAgent:         clientInfo.userAgent,
+			ClientName:        c.client.name,
+			ClientVersion:     c.client.version,
+			AndroidSDKVersion: c.client.androidVersion,
+			UserAgent:         c.client.userAgent,
 		},
 	}
 }
 
-func prepareInnertubePlaylistData(ID string, continuation bool, clientInfo clientInfo) innertubeRequest {
-	context := prepareInnertubeContext(clientInfo)
+func (c *Client) prepareInnertubePlaylistData(ID string, continuation bool) innertubeRequest {
+	context := c.prepareInnertubeContext()
 
 	if continuation {
 		return innertubeRequest{
@@ -292,8 +559,8 @@ func (c *Client) GetPlaylistContext(ctx context.Context, url string) (*Playlist,
 		return nil, fmt.Errorf("extractPlaylistID failed: %w", err)
 	}
 
-	data := prepareInnertubePlaylistData(id, false, *c.client)
-	body, err := c.httpPostBodyBytes(ctx, "https://www.youtube.com/youtubei/v1/browse?key="+c.client.key, data)
+	data := c.prepareInnertubePlaylistData(id, false)
+	body, err := c.httpPostBodyBytes(ctx, c.baseURL()+"/youtubei/v1/browse?key="+c.client.key, data)
 	if err != nil {
 		return nil, err
 	}
@@ -316,7 +583,10 @@ func (c *Client) GetStream(video *Video, format *Format) (io.ReadCloser, int64,
 }
 
 // GetStreamContext returns the stream and the total size for a specific format with a context.
+// The size is -1 if neither the format nor the response provide it.
 func (c *Client) GetStreamContext(ctx context.Context, video *Video, format *Format) (io.ReadCloser, int64, error) {
+	c = c.forVideo(video)
+
 	url, err := c.GetStreamURL(video, format)
 	if err != nil {
 		return nil, 0, err
@@ -335,12 +605,55 @@ func (c *Client) GetStreamContext(ctx context.Context, video *Video, format *For
 		contentLength = c.downloadOnce(req, w, format)
 	} else {
 		// we have length information, let's download by chunks!
-		c.downloadChunked(ctx, req, w, format)
+		c.downloadChunked(ctx, req, w, getChunks(contentLength, c.getChunkSize()))
 	}
 
 	return r, contentLength, nil
 }
 
+// GetStreamRange returns the stream of the bytes from start to end (inclusive) of a specific format
+// and the number of bytes it yields. The format must provide its ContentLength.
+func (c *Client) GetStreamRange(video *Video, format *Format, start, end int64) (io.ReadCloser, int64, error) {
+	return c.GetStreamRangeContext(context.Background(), video, format, start, end)
+}
+
+// GetStreamRangeContext returns the stream of the bytes from start to end (inclusive) of a specific format
+// and the number of bytes it yields, with a context. The format must provide its ContentLength.
+func (c *Client) GetStreamRangeContext(ctx context.Context, video *Video, format *Format, start, end int64) (io.ReadCloser, int64, error) {
+	if format == nil {
+		return nil, 0, ErrNoFormat
+	}
+
+	if format.ContentLength == 0 {
+		return nil, 0, ErrContentLengthUnknown
+	}
+
+	if end >= format.ContentLength {
+		end = format.ContentLength - 1
+	}
+
+	if start < 0 || start > end {
+		return nil, 0, fmt.Errorf("invalid range %d-%d for content length %d", start, end, format.ContentLength)
+	}
+
+	c = c.forVideo(video)
+
+	url, err := c.GetStreamURLContext(ctx, video, format)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	r, w := io.Pipe()
+	c.downloadChunked(ctx, req, w, getChunksInRange(start, end+1, c.getChunkSize()))
+
+	return r, end - start + 1, nil
+}
+
 func (c *Client) downloadOnce(req *http.Request, w *io.PipeWriter, _ *Format) int64 {
 	resp, err := c.httpDo(req)
 	if err != nil {
@@ -358,10 +671,8 @@ func (c *Client) downloadOnce(req *http.Request, w *io.PipeWriter, _ *Format) in
 		}
 	}()
 
-	contentLength := resp.Header.Get("Content-Length")
-	length, _ := strconv.ParseInt(contentLength, 10, 64)
-
-	return length
+	// ContentLength is -1 if the response was transparently decompressed or is chunked
+	return resp.ContentLength
 }
 
 func (c *Client) getChunkSize() int64 {
@@ -386,10 +697,12 @@ func (c *Client) getMaxRoutines(limit int) int {
 	return routines
 }
 
-func (c *Client) downloadChunked(ctx context.Context, req *http.Request, w *io.PipeWriter, format *Format) {
-	chunks := getChunks(format.ContentLength, c.getChunkSize())
+func (c *Client) downloadChunked(ctx context.Context, req *http.Request, w *io.PipeWriter, chunks []chunk) {
 	maxRoutines := c.getMaxRoutines(len(chunks))
 
+	// generated before the concurrent requests
+	c.assureConsentID()
+
 	cancelCtx, cancel := context.WithCancel(ctx)
 	abort := func(err error) {
 		w.CloseWithError(err)
@@ -446,6 +759,17 @@ func (c *Client) GetStreamURL(video *Video, format *Format) (string, error) {
 
 // GetStreamURLContext returns the url for a specific format with a context
 func (c *Client) GetStreamURLContext(ctx context.Context, video *Video, format *Format) (string, error) {
+	c = c.forVideo(video)
+
+	uri, err := c.getStreamURL(ctx, video, format)
+	if err != nil || c.PoToken == "" {
+		return uri, err
+	}
+
+	return addPoToken(uri, c.PoToken)
+}
+
+func (c *Client) getStreamURL(ctx context.Context, video *Video, format *Format) (string, error) {
 	if format == nil {
 		return "", ErrNoFormat
 	}
@@ -475,6 +799,22 @@ func (c *Client) GetStreamURLContext(ctx context.Context, video *Video, format *
 	return uri, err
 }
 
+// addPoToken appends the proof of origin token to a stream URL.
+// The rest of the query is kept as is, as the signed URLs depend on its order and escaping.
+func addPoToken(streamURL, token string) (string, error) {
+	uri, err := url.Parse(streamURL)
+	if err != nil {
+		return "", err
+	}
+
+	if uri.RawQuery != "" {
+		uri.RawQuery += "&"
+	}
+	uri.RawQuery += "pot=" + url.QueryEscape(token)
+
+	return uri.String(), nil
+}
+
 // httpDo sends an HTTP request and returns an HTTP response.
 func (c *Client) httpDo(req *http.Request) (*http.Response, error) {
 	client := c.HTTPClient
@@ -482,14 +822,24 @@ func (c *Client) httpDo(req *http.Request) (*http.Response, error) {
 		client = http.DefaultClient
 	}
 
-	req.Header.Set("User-Agent", c.client.userAgent)
+	userAgent := c.UserAgent
+	if userAgent == "" {
+		userAgent = c.client.userAgent
+	}
+
+	req.Header.Set("User-Agent", userAgent)
 	req.Header.Set("Origin", "https://youtube.com")
 	req.Header.Set("Sec-Fetch-Mode", "navigate")
 
-	if len(c.consentID) == 0 {
-		c.consentID = strconv.Itoa(rand.Intn(899) + 100) //nolint:gosec
+	for name, values := range c.Header {
+		req.Header.Del(name)
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
 	}
 
+	c.assureConsentID()
+
 	req.AddCookie(&http.Cookie{
 		Name:   "CONSENT",
 		Value:  "YES+cb.20210328-17-p0.en+FX+" + c.consentID,
@@ -497,17 +847,93 @@ func (c *Client) httpDo(req *http.Request) (*http.Response, error) {
 		Domain: ".youtube.com",
 	})
 
-	res, err := client.Do(req)
+	log := c.logger().With("method", req.Method, "url", req.URL)
+
+	for attempt := 0; ; attempt++ {
+		res, err := client.Do(req)
 
-	log := slog.With("method", req.Method, "url", req.URL)
+		if err != nil {
+			log.Debug("HTTP request failed", "error", err)
+		} else {
+			log.Debug("HTTP request succeeded", "status", res.Status)
+		}
+
+		if attempt >= c.MaxRetries || !isRetryable(res, err) || req.Context().Err() != nil {
+			return res, err
+		}
+
+		if res != nil {
+			res.Body.Close()
+		}
 
+		if err := c.waitForRetry(req, attempt); err != nil {
+			return nil, err
+		}
+
+		log.Debug("Retrying HTTP request", "attempt", attempt+1)
+	}
+}
+
+// assureConsentID generates the ID of the consent cookie once
+func (c *Client) assureConsentID() {
+	if len(c.consentID) == 0 {
+		c.consentID = strconv.Itoa(rand.Intn(899) + 100) //nolint:gosec
+	}
+}
+
+// isRetryable reports whether a request failed for a probably transient reason
+func isRetryable(res *http.Response, err error) bool {
 	if err != nil {
-		log.Debug("HTTP request failed", "error", err)
-	} else {
-		log.Debug("HTTP request succeeded", "status", res.Status)
+		return true
 	}
 
-	return res, err
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError
+}
+
+// maxRetryDelay limits the exponential backoff, unless RetryBackoff is longer
+const maxRetryDelay = 30 * time.Second
+
+// retryDelay returns the delay before the given retry without jitter
+func (c *Client) retryDelay(attempt int) time.Duration {
+	backoff := c.RetryBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	limit := max(backoff, maxRetryDelay)
+
+	// doubling step by step can't overflow like a shift by the attempt
+	delay := backoff
+	for i := 0; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+
+	return min(delay, limit)
+}
+
+// waitForRetry sleeps with exponential backoff and jitter, and rewinds the request body
+func (c *Client) waitForRetry(req *http.Request, attempt int) error {
+	delay := c.retryDelay(attempt)
+	delay += time.Duration(rand.Int63n(int64(delay)/2 + 1)) //nolint:gosec
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-req.Context().Done():
+		return req.Context().Err()
+	case <-timer.C:
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		req.Body = body
+	}
+
+	return nil
 }
 
 // httpGet does a HTTP GET request, checks the response to be a 200 OK and returns it
@@ -523,13 +949,22 @@ func (c *Client) httpGet(ctx context.Context, url string) (*http.Response, error
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
-		return nil, ErrUnexpectedStatusCode(resp.StatusCode)
+		return nil, c.unexpectedStatus(resp)
 	}
 
 	return resp, nil
 }
 
+// unexpectedStatus closes the response and logs the beginning of its body for debugging
+func (c *Client) unexpectedStatus(resp *http.Response) error {
+	defer resp.Body.Close()
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	c.logger().Debug("Unexpected HTTP status", "url", resp.Request.URL, "status", resp.Status, "body", string(snippet))
+
+	return ErrUnexpectedStatusCode(resp.StatusCode)
+}
+
 // httpGetBodyBytes reads the whole HTTP body and returns it
 func (c *Client) httpGetBodyBytes(ctx context.Context, url string) ([]byte, error) {
 	resp, err := c.httpGet(ctx, url)
@@ -564,8 +999,7 @@ func (c *Client) httpPost(ctx context.Context, url string, body interface{}) (*h
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
-		return nil, ErrUnexpectedStatusCode(resp.StatusCode)
+		return nil, c.unexpectedStatus(resp)
 	}
 
 	return resp, nil
@@ -1,7 +1,17 @@
 package youtube
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
 	"io"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -274,3 +284,429 @@ func TestClient_httpGetBodyBytes(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_httpDoRetries(t *testing.T) {
+	tests := []struct {
+		name             string
+		statusCodes      []int
+		expectedStatus   int
+		expectedAttempts int32
+	}{
+		{"success", []int{200}, 200, 1},
+		{"retry on 503", []int{503, 503, 200}, 200, 3},
+		{"retry on 429", []int{429, 200}, 200, 2},
+		{"give up after max retries", []int{500, 500, 500, 500}, 500, 3},
+		{"no retry on 404", []int{404, 200}, 404, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCodes[attempts.Add(1)-1])
+			}))
+			defer server.Close()
+
+			client := Client{
+				client:       &AndroidClient,
+				MaxRetries:   2,
+				RetryBackoff: time.Millisecond,
+			}
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := client.httpDo(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectedAttempts, attempts.Load())
+		})
+	}
+}
+
+func TestClient_httpDoRetriesCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := Client{
+		client:       &AndroidClient,
+		MaxRetries:   5,
+		RetryBackoff: time.Hour,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = client.httpDo(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClient_retryDelay(t *testing.T) {
+	client := Client{}
+	assert.Equal(t, time.Second, client.retryDelay(0))
+	assert.Equal(t, 16*time.Second, client.retryDelay(4))
+	assert.Equal(t, maxRetryDelay, client.retryDelay(5))
+
+	// a shift by these attempts would overflow
+	assert.Equal(t, maxRetryDelay, client.retryDelay(40))
+	assert.Equal(t, maxRetryDelay, client.retryDelay(100))
+
+	// a longer backoff isn't shortened
+	client.RetryBackoff = time.Minute
+	assert.Equal(t, time.Minute, client.retryDelay(3))
+}
+
+func TestClient_httpDoUserAgent(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		override  string
+		userAgent string
+	}{
+		{"client default", "", WebClient.userAgent},
+		{"override", "custom/1.0", "custom/1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := Client{client: &WebClient, UserAgent: tt.override}
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := client.httpDo(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.userAgent, userAgent)
+		})
+	}
+}
+
+func TestClient_httpDoHeader(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+	}))
+	defer server.Close()
+
+	client := Client{client: &WebClient, Header: http.Header{
+		"Origin":                {"https://www.youtube.com"},
+		"X-Youtube-Client-Name": {"1"},
+	}}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := client.httpDo(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{"https://www.youtube.com"}, header.Values("Origin"))
+	assert.Equal(t, "1", header.Get("X-YouTube-Client-Name"))
+	assert.Equal(t, WebClient.userAgent, header.Get("User-Agent"))
+}
+
+func TestClient_httpDoCookies(t *testing.T) {
+	var cookies []*http.Cookie
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookies = r.Cookies()
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	jar.SetCookies(serverURL, []*http.Cookie{{Name: "SID", Value: "session"}})
+
+	client := Client{client: &WebClient, HTTPClient: &http.Client{Jar: jar}}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := client.httpDo(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	names := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		names = append(names, cookie.Name)
+	}
+	assert.ElementsMatch(t, []string{"CONSENT", "SID"}, names)
+}
+
+func TestClient_downloadOnceCompressed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("Accept-Encoding"), "gzip")
+
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte("video data"))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	client := Client{client: &WebClient}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	r, w := io.Pipe()
+	length := client.downloadOnce(req, w, nil)
+	assert.EqualValues(t, -1, length)
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "video data", string(data))
+}
+
+func TestClient_httpDoLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := Client{
+		client: &WebClient,
+		Logger: slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := client.httpDo(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Contains(t, buf.String(), `msg="HTTP request succeeded" method=GET url=`+server.URL)
+}
+
+func TestClient_prepareInnertubeContext(t *testing.T) {
+	client := Client{client: &AndroidClient}
+	innertube := client.prepareInnertubeContext()
+	assert.Equal(t, "en", innertube.Client.HL)
+	assert.Equal(t, "US", innertube.Client.GL)
+	assert.Equal(t, AndroidClient.name, innertube.Client.ClientName)
+
+	client.Country, client.Language = "DE", "de"
+	innertube = client.prepareInnertubeContext()
+	assert.Equal(t, "de", innertube.Client.HL)
+	assert.Equal(t, "DE", innertube.Client.GL)
+}
+
+func TestClient_GetVideoLocalizedReason(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data innertubeRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&data))
+		assert.Equal(t, "DE", data.Context.Client.GL)
+
+		if data.Context.Client.HL == "de" {
+			io.WriteString(w, `{"playabilityStatus": {"status": "ERROR", "reason": "Dieses Video wurde vom Uploader entfernt", "playableInEmbed": true}}`)
+			return
+		}
+		io.WriteString(w, `{"playabilityStatus": {"status": "ERROR", "reason": "This video has been removed by the uploader", "playableInEmbed": true}}`)
+	}))
+	defer server.Close()
+
+	client := Client{BaseURL: server.URL, Country: "DE", Language: "de"}
+
+	_, err := client.GetVideo("BaW_jenozKc")
+	assert.ErrorIs(t, err, ErrVideoRemoved)
+
+	available, err := client.IsAvailable("BaW_jenozKc")
+	assert.False(t, available)
+	assert.ErrorIs(t, err, ErrVideoRemoved)
+}
+
+func TestClient_GetVideosKeepsOrder(t *testing.T) {
+	client := Client{MaxRoutines: 2}
+
+	// invalid IDs fail without requests
+	videos, errs := client.GetVideos([]string{"short", "<invalid>", "tooLongVideoID"})
+	require.Len(t, videos, 3)
+	require.Len(t, errs, 3)
+
+	assert.ErrorIs(t, errs[0], ErrVideoIDMinLength)
+	assert.ErrorIs(t, errs[1], ErrInvalidCharactersInVideoID)
+	assert.ErrorIs(t, errs[2], ErrVideoIDMaxLength)
+	assert.Equal(t, []*Video{nil, nil, nil}, videos)
+}
+
+func TestClient_GetVideosRecordsClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/youtubei/v1/player" {
+			// the embed page for the signature timestamp of the embedded client
+			http.NotFound(w, r)
+			return
+		}
+
+		var data innertubeRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&data))
+
+		// the age-restricted video is only playable by the embedded client
+		if data.VideoID == "ageRestrict" && data.Context.Client.ClientName != EmbeddedClient.name {
+			io.WriteString(w, `{"playabilityStatus": {"status": "LOGIN_REQUIRED", "reason": "Sign in to confirm your age"}}`)
+			return
+		}
+
+		fmt.Fprintf(w, `{
+			"playabilityStatus": {"status": "OK", "playableInEmbed": true},
+			"streamingData": {"formats": [{"itag": 18, "url": "https://example.com/18", "mimeType": "video/mp4"}]},
+			"videoDetails": {"videoId": %q}
+		}`, data.VideoID)
+	}))
+	defer server.Close()
+
+	client := Client{BaseURL: server.URL, MaxRoutines: 2}
+
+	ids := []string{"BaW_jenozKc", "ageRestrict", "jNQXAC9IVRw", "dQw4w9WgXcQ"}
+	videos, errs := client.GetVideos(ids)
+	for i, id := range ids {
+		require.NoError(t, errs[i])
+		assert.Equal(t, id, videos[i].ID)
+	}
+
+	assert.Same(t, &EmbeddedClient, videos[1].client)
+	for _, i := range []int{0, 2, 3} {
+		assert.Same(t, &DefaultClient, videos[i].client)
+	}
+
+	// the caller keeps its client, but streams of the age-restricted video use the embedded one
+	assert.Same(t, &DefaultClient, client.client)
+	assert.Same(t, &EmbeddedClient, client.forVideo(videos[1]).client)
+	assert.Same(t, &client, client.forVideo(videos[0]))
+}
+
+func TestClient_GetVideoWithBaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/youtubei/v1/player", r.URL.Path)
+		io.WriteString(w, `{
+			"playabilityStatus": {"status": "OK", "playableInEmbed": true},
+			"streamingData": {"formats": [{"itag": 18, "url": "https://example.com/18", "mimeType": "video/mp4"}]},
+			"videoDetails": {"videoId": "BaW_jenozKc", "title": "served by the test server"}
+		}`)
+	}))
+	defer server.Close()
+
+	client := Client{BaseURL: server.URL + "/"}
+
+	video, err := client.GetVideo("BaW_jenozKc")
+	require.NoError(t, err)
+	assert.Equal(t, "served by the test server", video.Title)
+	require.Len(t, video.Formats, 1)
+}
+
+func TestClient_GetVideoFallsBackToVideoPage(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/youtubei/v1/player", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"playabilityStatus": {"status": "OK", "playableInEmbed": true}, "videoDetails": {"title": "no formats"}}`)
+	})
+	mux.HandleFunc("/watch", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `<script>var ytInitialPlayerResponse = {"playabilityStatus": {"status": "OK"}, "streamingData": {"formats": [{"itag": 18, "url": "https://example.com/18", "mimeType": "video/mp4"}]}, "videoDetails": {"title": "from the video page"}};</script>`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := Client{BaseURL: server.URL}
+
+	video, err := client.GetVideo("BaW_jenozKc")
+	require.NoError(t, err)
+	assert.Equal(t, "from the video page", video.Title)
+	require.Len(t, video.Formats, 1)
+}
+
+func TestClient_EstimatePlaylistSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request innertubeRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		switch request.VideoID {
+		case "AAAAAAAAAAA":
+			io.WriteString(w, `{"playabilityStatus": {"status": "OK"}, "videoDetails": {"lengthSeconds": "10"},
+				"streamingData": {"formats": [{"itag": 18, "url": "https://example.com/18", "quality": "medium", "contentLength": "1000"}]}}`)
+		case "BBBBBBBBBBB":
+			io.WriteString(w, `{"playabilityStatus": {"status": "OK"}, "videoDetails": {"lengthSeconds": "10"},
+				"streamingData": {"formats": [{"itag": 18, "url": "https://example.com/18", "quality": "medium", "bitrate": 800}]}}`)
+		default:
+			io.WriteString(w, `{"playabilityStatus": {"status": "OK"}, "videoDetails": {"lengthSeconds": "10"},
+				"streamingData": {"formats": [{"itag": 22, "url": "https://example.com/22", "quality": "hd720", "contentLength": "5000"}]}}`)
+		}
+	}))
+	defer server.Close()
+
+	client := Client{BaseURL: server.URL}
+
+	size, err := client.EstimatePlaylistSize([]string{"AAAAAAAAAAA", "BBBBBBBBBBB"}, "medium")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1000+800*10/8, size)
+
+	size, err = client.EstimatePlaylistSize([]string{"AAAAAAAAAAA", "CCCCCCCCCCC"}, "medium")
+	assert.EqualError(t, err, "no format with quality medium for CCCCCCCCCCC")
+	assert.EqualValues(t, 1000, size)
+}
+
+func TestClient_IsAvailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request innertubeRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		switch request.VideoID {
+		case "AAAAAAAAAAA":
+			io.WriteString(w, `{"playabilityStatus": {"status": "OK"}}`)
+		case "CCCCCCCCCCC":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			io.WriteString(w, `{"playabilityStatus": {"status": "ERROR", "reason": "This video has been removed by the uploader"}}`)
+		}
+	}))
+	defer server.Close()
+
+	client := Client{BaseURL: server.URL}
+
+	available, err := client.IsAvailable("AAAAAAAAAAA")
+	require.NoError(t, err)
+	assert.True(t, available)
+
+	available, err = client.IsAvailable("BBBBBBBBBBB")
+	assert.False(t, available)
+	assert.ErrorIs(t, err, ErrVideoRemoved)
+	assert.NotErrorIs(t, err, ErrAvailabilityCheckFailed)
+
+	// failed checks are told apart from unavailable videos
+	_, err = client.IsAvailable("CCCCCCCCCCC")
+	assert.ErrorIs(t, err, ErrAvailabilityCheckFailed)
+	assert.ErrorIs(t, err, ErrUnexpectedStatusCode(http.StatusInternalServerError))
+}
+
+func TestClient_IsAvailableWithoutPlayer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/youtubei/v1/player" {
+			t.Errorf("unexpected request of %s", r.URL)
+			http.NotFound(w, r)
+			return
+		}
+
+		io.WriteString(w, `{"playabilityStatus": {"status": "OK"}}`)
+	}))
+	defer server.Close()
+
+	// web clients need the player for the signature timestamp of stream URLs, but not to check the status
+	for _, innertubeClient := range []*clientInfo{&WebClient, &EmbeddedClient} {
+		client := Client{BaseURL: server.URL, client: innertubeClient}
+
+		available, err := client.IsAvailable("AAAAAAAAAAA")
+		require.NoError(t, err)
+		assert.True(t, available)
+	}
+}
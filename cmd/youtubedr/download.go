@@ -25,6 +25,7 @@ var (
 	ffmpegCheck error
 	outputFile  string
 	outputDir   string
+	rateLimit   int64
 )
 
 func init() {
@@ -32,6 +33,7 @@ func init() {
 
 	downloadCmd.Flags().StringVarP(&outputFile, "filename", "o", "", "The output file, the default is genated by the video title.")
 	downloadCmd.Flags().StringVarP(&outputDir, "directory", "d", ".", "The output directory.")
+	downloadCmd.Flags().Int64Var(&rateLimit, "rate-limit", 0, "Limit the download speed in bytes per second, 0 means unlimited.")
 	addVideoSelectionFlags(downloadCmd.Flags())
 }
 
@@ -43,6 +45,13 @@ func download(id string) error {
 
 	log.Println("download to directory", outputDir)
 
+	// the file which was actually written, e.g. after resolving a collision
+	downloader.OnComplete = func(path string, err error) {
+		if err == nil {
+			log.Println("downloaded", path)
+		}
+	}
+
 	if strings.HasPrefix(outputQuality, "hd") {
 		if err := checkFFMPEG(); err != nil {
 			return err
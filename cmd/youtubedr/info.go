@@ -22,6 +22,7 @@ type VideoFormat struct {
 	Size          int64
 	Bitrate       int
 	MimeType      string
+	Ciphered      bool
 }
 
 type VideoInfo struct {
@@ -75,6 +76,7 @@ var infoCmd = &cobra.Command{
 				Bitrate:       bitrate,
 				MimeType:      format.MimeType,
 				Language:      format.LanguageDisplayName(),
+				Ciphered:      format.IsCiphered(),
 			})
 		}
 
@@ -22,6 +22,9 @@ func (c *Client) decipherURL(ctx context.Context, videoID string, cipher string)
 	if err != nil {
 		return "", err
 	}
+	if uri.Scheme == "" || uri.Host == "" {
+		return "", fmt.Errorf("%w: no URL in the signature cipher", ErrInvalidStreamURL)
+	}
 	query := uri.Query()
 
 	config, err := c.getPlayerConfig(ctx, videoID)
@@ -29,12 +32,29 @@ func (c *Client) decipherURL(ctx context.Context, videoID string, cipher string)
 		return "", err
 	}
 
-	// decrypt s-parameter
-	bs, err := config.decrypt([]byte(params.Get("s")))
-	if err != nil {
-		return "", err
+	// the name of the signature parameter
+	sp := params.Get("sp")
+	if sp == "" {
+		sp = "signature"
+	}
+
+	if sig := params.Get("sig"); sig != "" {
+		// already deciphered
+		query.Set(sp, sig)
+	} else if s := params.Get("s"); s != "" {
+		// decrypt s-parameter
+		bs, err := config.decrypt([]byte(s))
+		if err != nil {
+			return "", err
+		}
+		if len(bs) == 0 {
+			// the decipher functions of the player have probably changed
+			return "", fmt.Errorf("%w: empty signature, the player may have changed", ErrInvalidStreamURL)
+		}
+		query.Set(sp, string(bs))
+	} else {
+		return "", fmt.Errorf("%w: no signature in the signature cipher", ErrInvalidStreamURL)
 	}
-	query.Add(params.Get("sp"), string(bs))
 
 	query, err = c.decryptNParam(config, query)
 	if err != nil {
@@ -60,7 +80,7 @@ func (c *Client) unThrottle(ctx context.Context, videoID string, urlString strin
 
 	// for debugging
 	if artifactsFolder != "" {
-		writeArtifact("video-"+videoID+".url", []byte(uri.String()))
+		c.writeArtifact("video-"+videoID+".url", []byte(uri.String()))
 	}
 
 	query, err := c.decryptNParam(config, uri.Query())
@@ -74,15 +94,15 @@ func (c *Client) unThrottle(ctx context.Context, videoID string, urlString strin
 
 func (c *Client) decryptNParam(config playerConfig, query url.Values) (url.Values, error) {
 	// decrypt n-parameter
-	nSig := query.Get("v")
-	log := Logger.With("n", nSig)
+	nSig := query.Get("n")
+	log := c.logger().With("n", nSig)
 
 	if nSig != "" {
 		nDecoded, err := config.decodeNsig(nSig)
 		if err != nil {
 			return nil, fmt.Errorf("unable to decode nSig: %w", err)
 		}
-		query.Set("v", nDecoded)
+		query.Set("n", nDecoded)
 		log = log.With("decoded", nDecoded)
 	}
 
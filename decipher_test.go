@@ -0,0 +1,150 @@
+package youtube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPlayerConfig is a trimmed down excerpt of a base.js player
+var testPlayerConfig = playerConfig(`var Mt={splice:function(a,b){a.splice(0,b)},
+reverse:function(a){a.reverse()},
+EQ:function(a,b){var c=a[0];a[0]=a[b%a.length];a[b%a.length]=c}};
+Npa=function(a){a=a.split("");Mt.splice(a,1);Mt.reverse(a,2);Mt.EQ(a,3);return a.join("")};
+var Abc=function(a){var b=a.split("");b.reverse();return b.join("")+"_"};
+a.D&&(b=a.get("n"))&&(b=Xqa[0](b),a.set("n",b),Xqa.length||Abc(""));
+var cfg={signatureTimestamp:19716};
+`)
+
+func TestPlayerConfig_decrypt(t *testing.T) {
+	bs, err := testPlayerConfig.decrypt([]byte("abcdefgh"))
+	require.NoError(t, err)
+	assert.Equal(t, "egfhdcb", string(bs))
+}
+
+func TestPlayerConfig_decodeNsig(t *testing.T) {
+	decoded, err := testPlayerConfig.decodeNsig("abc")
+	require.NoError(t, err)
+	assert.Equal(t, "cba_", decoded)
+}
+
+func TestClient_decryptNParam(t *testing.T) {
+	query, err := testClient.decryptNParam(testPlayerConfig, url.Values{
+		"n":    {"abc"},
+		"itag": {"18"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "cba_", query.Get("n"))
+	assert.Equal(t, "18", query.Get("itag"))
+}
+
+func TestClient_decryptNParamLogger(t *testing.T) {
+	var buf bytes.Buffer
+	client := Client{Logger: slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))}
+
+	_, err := client.decryptNParam(testPlayerConfig, url.Values{"n": {"abc"}})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "msg=nParam n=abc decoded=cba_")
+}
+
+func TestClient_decipherURL(t *testing.T) {
+	const playerPath = "/s/player/decipher/player_ias.vflset/en_US/base.js"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/embed/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<script>{"jsUrl":"%s"}</script>`, playerPath)
+	})
+	mux.HandleFunc(playerPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testPlayerConfig)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := Client{client: &WebClient, BaseURL: server.URL}
+
+	tests := []struct {
+		name     string
+		cipher   string
+		expected string
+		err      error
+	}{
+		{
+			name:     "encrypted signature",
+			cipher:   "s=abcdefgh&sp=sig&url=https%3A%2F%2Fexample.com%2Fvideoplayback%3Fn%3Dabc",
+			expected: "https://example.com/videoplayback?n=cba_&sig=egfhdcb",
+		},
+		{
+			name:     "plain signature",
+			cipher:   "sig=plain&url=https%3A%2F%2Fexample.com%2Fvideoplayback%3Fn%3Dabc",
+			expected: "https://example.com/videoplayback?n=cba_&signature=plain",
+		},
+		{
+			name:   "missing URL",
+			cipher: "s=abcdefgh&sp=sig",
+			err:    ErrInvalidStreamURL,
+		},
+		{
+			name:   "missing signature",
+			cipher: "s=&sp=sig&url=https%3A%2F%2Fexample.com%2Fvideoplayback",
+			err:    ErrInvalidStreamURL,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uri, err := client.decipherURL(context.Background(), "BaW_jenozKc", tt.cipher)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, uri)
+		})
+	}
+}
+
+func TestClient_videoDataByInnertube(t *testing.T) {
+	const playerPath = "/s/player/sts/player_ias.vflset/en_US/base.js"
+
+	var request innertubeRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("/embed/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<script>{"jsUrl":"%s"}</script>`, playerPath)
+	})
+	mux.HandleFunc(playerPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testPlayerConfig)
+	})
+	mux.HandleFunc("/youtubei/v1/player", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(body, &request))
+		w.Write([]byte("{}"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := Client{client: &WebClient, BaseURL: server.URL, PoToken: "token"}
+
+	_, err := client.videoDataByInnertube(context.Background(), "BaW_jenozKc")
+	require.NoError(t, err)
+	assert.Equal(t, 19716, request.PlaybackContext.ContentPlaybackContext.SignatureTimestamp)
+	require.NotNil(t, request.Integrity)
+	assert.Equal(t, "token", request.Integrity.PoToken)
+
+	uri, err := addPoToken("https://example.com/videoplayback?itag=18", client.PoToken)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/videoplayback?itag=18&pot=token", uri)
+
+	// the signed query isn't sorted or escaped again
+	uri, err = addPoToken("https://example.com/videoplayback?sparams=itag%2Cn&n=cba_&itag=18&sig=AB%3D%3D", "a/b+c=")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/videoplayback?sparams=itag%2Cn&n=cba_&itag=18&sig=AB%3D%3D&pot=a%2Fb%2Bc%3D", uri)
+}
@@ -2,31 +2,138 @@ package downloader
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"hash"
 	"io"
+	"log/slog"
+	"net/url"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/kkdai/youtube/v2"
 	"github.com/vbauerster/mpb/v5"
 	"github.com/vbauerster/mpb/v5/decor"
 )
 
+// ErrIncompleteDownload is returned if a stream ended before its expected size, e.g. on a dropped connection
+var ErrIncompleteDownload = errors.New("incomplete download")
+
+// ErrChecksumMismatch is returned if the checksum of a download differs from ExpectedChecksum
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// CollisionPolicy decides what happens if the output file of a download already exists
+type CollisionPolicy int
+
+const (
+	CollisionOverwrite    CollisionPolicy = iota // replace the existing file
+	CollisionSkip                                // keep the existing file and skip the download
+	CollisionAppendNumber                        // write to a new file like "title (1).mp4"
+)
+
 // Downloader offers high level functions to download videos into files
 type Downloader struct {
 	youtube.Client
-	OutputDir string // optional directory to store the files
+	OutputDir    string // optional directory to store the files
+	KeepPartial  bool   // keep incomplete .part files if a download fails or gets cancelled
+	Resume       bool   // continue incomplete .part files instead of starting over, implies KeepPartial
+	SkipExisting bool   // skip files which already exist with the content length of the format or as finished recording or merged file, but hash them and write their info JSON
+
+	// TempDir optionally is the directory for .part files and files before muxing, e.g. os.TempDir()
+	// if the output directory is a slow network mount. Default is the directory of the output file, not os.TempDir(),
+	// as a rename within a file system avoids copying every download, and /tmp may be cleared before resuming.
+	TempDir string
+
+	// OnCollision decides what happens if the output file exists but isn't skipped by SkipExisting,
+	// e.g. when several titles result in the same filename. Default is CollisionOverwrite.
+	OnCollision CollisionPolicy
+
+	// ProgressUpdates optionally receives the progress of running downloads.
+	// Updates are dropped if the receiver isn't ready.
+	ProgressUpdates chan<- Progress
+
+	// OnProgress is optionally called with the progress of running downloads on every written chunk.
+	// It runs on the download goroutine, so it should return quickly.
+	OnProgress func(Progress)
+
+	// FilenameTemplate optionally names the files with text/template, e.g. "{{.Author}}/{{.Title}} [{{.ID}}].{{.Ext}}".
+	// The fields are ID, Title, Author, Quality and Ext. Every segment of the path is sanitized.
+	FilenameTemplate string
+
+	// CodecPreference optionally sets the order of video codecs when formats have the same resolution,
+	// e.g. "av1", "vp9", "h264" for smaller files. Default is h264, vp9 and av1 for compatibility.
+	CodecPreference []string
+
+	// ContainerPreference optionally prefers formats of a container, e.g. "mp4" for editors, over others
+	// of the same resolution. Other containers are used if the preferred one isn't available.
+	ContainerPreference string
+
+	// CopyBufferSize optionally sets the size of the buffer for writing streams, e.g. 1 MiB for fast connections.
+	// Default is the 32 KiB of io.Copy.
+	CopyBufferSize int
+
+	// RateLimit optionally limits the download speed in bytes per second. Zero means unlimited.
+	RateLimit int64
+
+	// OnComplete is optionally called with the output file when a download finished, err is nil on success.
+	// Updates of ProgressUpdates are not sent anymore at this point, but the channel isn't closed.
+	OnComplete func(path string, err error)
+
+	// FileMode optionally sets the permissions of written files before the umask, e.g. 0o600 for private downloads.
+	// Default is 0o666 for streams and 0o644 for other files.
+	FileMode os.FileMode
+
+	// DirMode optionally sets the permissions of created directories before the umask. Default is 0o755.
+	DirMode os.FileMode
+
+	// Hash optionally computes a checksum of files written by Download, DownloadRange, DownloadLive or DownloadComposite, e.g. sha256.New().
+	// It is reset on every call, so downloads must not run concurrently when set.
+	Hash hash.Hash
+
+	// ExpectedChecksum optionally is the hex encoded digest the download must have with Hash.
+	// Otherwise Download removes the file and returns ErrChecksumMismatch.
+	ExpectedChecksum string
+
+	// WriteInfoJSON writes the metadata of the video as InfoJSON to the output file + ".info.json"
+	// after Download, DownloadRange, DownloadLive or DownloadComposite succeeded, also if the output file was skipped.
+	WriteInfoJSON bool
 }
 
-func (dl *Downloader) getOutputFile(v *youtube.Video, format *youtube.Format, outputFile string) (string, error) {
+// GetOutputFile returns the path of the output file for the given arguments before OnCollision is applied,
+// so Download may write to another file like "title (1).mp4". OnComplete receives the actual path.
+// The filename is derived from FilenameTemplate or the title and the mime type if outputFile is empty.
+// OutputDir and the directories of the template are created if they don't exist yet.
+func (dl *Downloader) GetOutputFile(v *youtube.Video, format *youtube.Format, outputFile string) (string, error) {
+	if outputFile == "" && dl.FilenameTemplate != "" {
+		rendered, err := renderFilename(dl.FilenameTemplate, v, format)
+		if err != nil {
+			return "", err
+		}
+
+		if dir := filepath.Join(dl.OutputDir, filepath.Dir(rendered)); dir != "." {
+			if err := os.MkdirAll(dir, dl.dirMode()); err != nil {
+				return "", err
+			}
+		}
+		outputFile = rendered
+	}
+
 	if outputFile == "" {
 		outputFile = SanitizeFilename(v.Title)
-		outputFile += pickIdealFileExtension(format.MimeType)
+		if format != nil {
+			outputFile += pickIdealFileExtension(format.MimeType)
+		}
 	}
 
 	if dl.OutputDir != "" {
-		if err := os.MkdirAll(dl.OutputDir, 0o755); err != nil {
+		if err := os.MkdirAll(dl.OutputDir, dl.dirMode()); err != nil {
 			return "", err
 		}
 		outputFile = filepath.Join(dl.OutputDir, outputFile)
@@ -36,36 +143,583 @@ func (dl *Downloader) getOutputFile(v *youtube.Video, format *youtube.Format, ou
 }
 
 // Download : Starting download video by arguments.
-func (dl *Downloader) Download(ctx context.Context, v *youtube.Video, format *youtube.Format, outputFile string) error {
-	youtube.Logger.Info(
+// The video is written to a .part file which is renamed once the download is complete.
+func (dl *Downloader) Download(ctx context.Context, v *youtube.Video, format *youtube.Format, outputFile string) (err error) {
+	dl.logger().Info(
 		"Downloading video",
 		"id", v.ID,
 		"quality", format.Quality,
 		"mimeType", format.MimeType,
 	)
-	destFile, err := dl.getOutputFile(v, format, outputFile)
+	destFile, err := dl.GetOutputFile(v, format, outputFile)
 	if err != nil {
 		return err
 	}
+	defer func() {
+		dl.complete(destFile, err)
+	}()
+
+	var skip bool
+	destFile, skip, err = dl.prepareOutput(v, destFile, func(path string) bool {
+		return isComplete(path, format)
+	})
+	if skip || err != nil {
+		return err
+	}
+
+	// write into a separate file, so incomplete downloads aren't mistaken for complete ones
+	partFile := dl.tempFile(destFile) + ".part"
+
+	offset, err := dl.getResumeOffset(partFile, format)
+	if err != nil {
+		return err
+	}
+
+	if dl.Hash != nil {
+		// the hash has to cover the bytes downloaded before
+		if err := hashFile(dl.Hash, partFile, offset); err != nil {
+			return err
+		}
+	}
+
+	if offset == format.ContentLength && offset > 0 {
+		dl.logger().Info("File already downloaded", "path", partFile)
+		return dl.finish(v, partFile, destFile)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if offset > 0 {
+		dl.logger().Info("Resuming download", "path", partFile, "offset", offset)
+		flags = os.O_WRONLY | os.O_APPEND
+	}
 
 	// Create output file
-	out, err := os.Create(destFile)
+	out, err := os.OpenFile(partFile, flags, dl.fileMode(0o666))
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = out
+	if dl.Hash != nil {
+		w = io.MultiWriter(out, dl.Hash)
+	}
+
+	err = dl.videoDLWorker(ctx, w, v, format, offset)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
 	if err != nil {
+		if !dl.KeepPartial && !dl.Resume {
+			os.Remove(partFile)
+		}
+		return err
+	}
+
+	return dl.finish(v, partFile, destFile)
+}
+
+// prepareOutput resets Hash and applies SkipExisting and OnCollision to the output file,
+// complete reports whether an existing file is complete for SkipExisting.
+// If the existing file is kept, skip is true and err is the result of skipExisting.
+func (dl *Downloader) prepareOutput(v *youtube.Video, destFile string, complete func(path string) bool) (path string, skip bool, err error) {
+	if dl.Hash != nil {
+		dl.Hash.Reset()
+	}
+
+	if dl.SkipExisting && complete(destFile) {
+		return destFile, true, dl.skipExisting(v, destFile)
+	}
+
+	if destFile, skip = dl.resolveCollision(destFile); skip {
+		return destFile, true, dl.skipExisting(v, destFile)
+	}
+
+	return destFile, false, nil
+}
+
+// downloadPart writes into a new .part file, which is moved to the output file by finish if write succeeded
+func (dl *Downloader) downloadPart(v *youtube.Video, destFile string, write func(w io.Writer) error) error {
+	partFile := dl.tempFile(destFile) + ".part"
+
+	out, err := os.OpenFile(partFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, dl.fileMode(0o666))
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = out
+	if dl.Hash != nil {
+		w = io.MultiWriter(out, dl.Hash)
+	}
+
+	err = write(w)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		if !dl.KeepPartial {
+			os.Remove(partFile)
+		}
 		return err
 	}
-	defer out.Close()
 
-	return dl.videoDLWorker(ctx, out, v, format)
+	return dl.finish(v, partFile, destFile)
+}
+
+// finish verifies the checksum of a complete part file and moves it to the output file
+func (dl *Downloader) finish(v *youtube.Video, partFile, destFile string) error {
+	if err := dl.verifyChecksum(); err != nil {
+		// resuming would keep the corrupted bytes
+		os.Remove(partFile)
+		return err
+	}
+
+	if err := moveFile(partFile, destFile); err != nil {
+		return err
+	}
+
+	return dl.writeInfoJSON(v, destFile)
+}
+
+// skipExisting keeps an existing output file, but hashes it and writes its info JSON like a download
+func (dl *Downloader) skipExisting(v *youtube.Video, destFile string) error {
+	dl.logger().Info("Skipping existing file", "path", destFile)
+
+	if dl.Hash != nil {
+		info, err := os.Stat(destFile)
+		if err != nil {
+			return err
+		}
+
+		if err := hashFile(dl.Hash, destFile, info.Size()); err != nil {
+			return err
+		}
+
+		if err := dl.verifyChecksum(); err != nil {
+			return err
+		}
+	}
+
+	return dl.writeInfoJSON(v, destFile)
+}
+
+// DownloadTo : Writes the video to any writer instead of a file, e.g. an HTTP response.
+func (dl *Downloader) DownloadTo(ctx context.Context, w io.Writer, v *youtube.Video, format *youtube.Format) error {
+	dl.logger().Info(
+		"Downloading video",
+		"id", v.ID,
+		"quality", format.Quality,
+		"mimeType", format.MimeType,
+	)
+
+	return dl.videoDLWorker(ctx, w, v, format, 0)
+}
+
+// DownloadAudioOnly : Downloads the best audio only format, optionally filtered by mime type, e.g. "audio/mp4" or "opus".
+// Without a mime type, the default audio track and m4a are preferred.
+func (dl *Downloader) DownloadAudioOnly(ctx context.Context, v *youtube.Video, outputFile string, mimetype string) error {
+	format, err := getAudioOnlyFormat(v, mimetype)
+	if err != nil {
+		return err
+	}
+
+	return dl.Download(ctx, v, format, outputFile)
+}
+
+// DownloadBestUnder : Downloads the best video with audio which is not higher than maxHeight.
+// Adaptive formats of a higher resolution than the progressive ones are merged via ffmpeg if it is installed.
+// If a download fails, the next best format is tried.
+func (dl *Downloader) DownloadBestUnder(ctx context.Context, v *youtube.Video, outputFile string, maxHeight int) error {
+	progressive := v.Formats.Kind(youtube.FormatProgressive).MaxHeight(maxHeight)
+	progressive.SortByCodecs(dl.codecPreference()...)
+	progressive.PreferContainer(dl.ContainerPreference)
+
+	var pairs [][2]*youtube.Format
+	if _, err := exec.LookPath("ffmpeg"); err == nil {
+		pairs = getAdaptivePairs(v, maxHeight, dl.codecPreference(), dl.ContainerPreference)
+	} else {
+		dl.logger().Debug("ffmpeg not found, only progressive formats are used", "error", err)
+	}
+
+	var err error
+	for _, pair := range pairs {
+		// progressive formats don't need to be merged
+		if len(progressive) > 0 && pair[0].Height <= progressive[0].Height {
+			break
+		}
+
+		err = dl.downloadComposite(ctx, outputFile, v, pair[0], pair[1])
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		dl.logger().Warn("Download failed, trying the next format", "itag", pair[0].ItagNo, "error", err)
+	}
+
+	if len(progressive) == 0 {
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("no format with video and audio found up to %dp", maxHeight)
+	}
+
+	for i := range progressive {
+		err = dl.Download(ctx, v, &progressive[i], outputFile)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		dl.logger().Warn("Download failed, trying the next format", "itag", progressive[i].ItagNo, "error", err)
+	}
+
+	return err
+}
+
+// getAdaptivePairs returns the video only formats not higher than maxHeight from best to worst,
+// each with the best audio format, preferably of the same container
+func getAdaptivePairs(v *youtube.Video, maxHeight int, codecs []string, container string) [][2]*youtube.Format {
+	audio := v.Formats.Kind(youtube.FormatAudioOnly)
+	if len(audio) == 0 {
+		return nil
+	}
+	audio.Sort()
+
+	videos := v.Formats.Kind(youtube.FormatVideoOnly).MaxHeight(maxHeight)
+	videos.SortByCodecs(codecs...)
+	videos.PreferContainer(container)
+
+	pairs := make([][2]*youtube.Format, len(videos))
+	for i := range videos {
+		// ffmpeg can't copy every audio codec into every container, e.g. AAC into WebM
+		sameContainer := slices.Clone(audio)
+		sameContainer.PreferContainer(videos[i].Container())
+		pairs[i] = [2]*youtube.Format{&videos[i], &sameContainer[0]}
+	}
+
+	return pairs
+}
+
+// logger returns the Logger of the client or the global youtube.Logger
+func (dl *Downloader) logger() *slog.Logger {
+	if dl.Logger != nil {
+		return dl.Logger
+	}
+
+	return youtube.Logger
+}
+
+// complete calls OnComplete with the result of a download
+func (dl *Downloader) complete(path string, err error) {
+	if dl.OnComplete != nil {
+		dl.OnComplete(path, err)
+	}
+}
+
+// Checksum returns the hex encoded digest of Hash after a download
+func (dl *Downloader) Checksum() string {
+	if dl.Hash == nil {
+		return ""
+	}
+
+	return hex.EncodeToString(dl.Hash.Sum(nil))
+}
+
+// verifyChecksum compares the checksum with ExpectedChecksum if it is set
+func (dl *Downloader) verifyChecksum() error {
+	if dl.ExpectedChecksum == "" {
+		return nil
+	}
+
+	if dl.Hash == nil {
+		return errors.New("ExpectedChecksum requires a Hash")
+	}
+
+	if checksum := dl.Checksum(); !strings.EqualFold(checksum, dl.ExpectedChecksum) {
+		return fmt.Errorf("%w: got %s, expected %s", ErrChecksumMismatch, checksum, dl.ExpectedChecksum)
+	}
+
+	return nil
+}
+
+// hashFile writes the first n bytes of a file into the hash
+func hashFile(h hash.Hash, path string, n int64) error {
+	if n == 0 {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.CopyN(h, f, n)
+	return err
+}
+
+// defaultCodecPreference prefers the video codecs which most players and editors support
+var defaultCodecPreference = []string{"h264", "vp9", "av1"}
+
+// codecPreference returns CodecPreference or the default preferring h264
+func (dl *Downloader) codecPreference() []string {
+	if len(dl.CodecPreference) > 0 {
+		return dl.CodecPreference
+	}
+
+	return defaultCodecPreference
+}
+
+// fileMode returns FileMode or the given default
+func (dl *Downloader) fileMode(defaultMode os.FileMode) os.FileMode {
+	if dl.FileMode != 0 {
+		return dl.FileMode
+	}
+
+	return defaultMode
+}
+
+// dirMode returns DirMode or the default 0o755
+func (dl *Downloader) dirMode() os.FileMode {
+	if dl.DirMode != 0 {
+		return dl.DirMode
+	}
+
+	return 0o755
+}
+
+// tempFile returns the path of a temporary file for the output file within TempDir
+func (dl *Downloader) tempFile(destFile string) string {
+	if dl.TempDir == "" {
+		return destFile
+	}
+
+	return filepath.Join(dl.TempDir, filepath.Base(destFile))
+}
+
+// moveFile renames a file, or copies it if TempDir is on another file system
+func moveFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+
+	var linkErr *os.LinkError
+	if !errors.As(err, &linkErr) || !errors.Is(linkErr.Err, syscall.EXDEV) {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+
+	if err = out.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(src)
+}
+
+// resolveCollision applies OnCollision if the file exists, skip is true if the download should be skipped
+func (dl *Downloader) resolveCollision(destFile string) (path string, skip bool) {
+	if _, err := os.Stat(destFile); err != nil {
+		return destFile, false
+	}
+
+	switch dl.OnCollision {
+	case CollisionSkip:
+		return destFile, true
+	case CollisionAppendNumber:
+		ext := filepath.Ext(destFile)
+		base := strings.TrimSuffix(destFile, ext)
+
+		for i := 1; ; i++ {
+			path = fmt.Sprintf("%s (%d)%s", base, i, ext)
+			if _, err := os.Stat(path); err != nil {
+				return path, false
+			}
+		}
+	}
+
+	return destFile, false
+}
+
+// isComplete checks whether the file exists with the content length of the format
+func isComplete(destFile string, format *youtube.Format) bool {
+	return hasSize(destFile, format.ContentLength)
+}
+
+// fileExists checks whether a file exists, e.g. for output files which are only created once complete
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// hasSize checks whether the file exists with the given size, which has to be known
+func hasSize(path string, size int64) bool {
+	if size <= 0 {
+		return false
+	}
+
+	info, err := os.Stat(path)
+	return err == nil && info.Size() == size
+}
+
+// getResumeOffset returns the number of bytes which are already downloaded.
+// Resuming requires the content length, otherwise the download starts over.
+func (dl *Downloader) getResumeOffset(destFile string, format *youtube.Format) (int64, error) {
+	if !dl.Resume || format.ContentLength == 0 {
+		return 0, nil
+	}
+
+	info, err := os.Stat(destFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if info.Size() > format.ContentLength {
+		// not the file we are looking for, start over
+		return 0, nil
+	}
+
+	return info.Size(), nil
+}
+
+// DownloadRange : Downloads the bytes from start to end (inclusive) of a format, see Format.ByteOffset.
+// The partial file is usually not seekable and may need to be remuxed to be playable.
+// Like Download, the range is written to a .part file first, but it isn't resumed.
+func (dl *Downloader) DownloadRange(ctx context.Context, v *youtube.Video, format *youtube.Format, outputFile string, start, end int64) (err error) {
+	dl.logger().Info(
+		"Downloading range",
+		"id", v.ID,
+		"quality", format.Quality,
+		"start", start,
+		"end", end,
+	)
+	destFile, err := dl.GetOutputFile(v, format, outputFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		dl.complete(destFile, err)
+	}()
+
+	var skip bool
+	destFile, skip, err = dl.prepareOutput(v, destFile, func(path string) bool {
+		return hasSize(path, rangeSize(format, start, end))
+	})
+	if skip || err != nil {
+		return err
+	}
+
+	stream, size, err := dl.GetStreamRangeContext(ctx, v, format, start, end)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	return dl.downloadPart(v, destFile, func(w io.Writer) error {
+		return dl.copyStream(ctx, w, stream, 0, size)
+	})
+}
+
+// rangeSize returns the number of bytes from start to end (inclusive) within the content length if it is known
+func rangeSize(format *youtube.Format, start, end int64) int64 {
+	if format.ContentLength > 0 && end >= format.ContentLength {
+		end = format.ContentLength - 1
+	}
+
+	return end - start + 1
+}
+
+// DownloadPreview : Downloads about the first seconds of a format, estimated from its size or bitrate, e.g. for previews.
+func (dl *Downloader) DownloadPreview(ctx context.Context, v *youtube.Video, format *youtube.Format, outputFile string, duration time.Duration) error {
+	end := format.ByteOffset(duration)
+	if end <= 0 {
+		return fmt.Errorf("unable to estimate the size of %s of itag %d", duration, format.ItagNo)
+	}
+
+	return dl.DownloadRange(ctx, v, format, outputFile, 0, end-1)
+}
+
+// DownloadLive : Records a live stream for the given duration as MPEG-TS, 0 records until the stream ends.
+// The recording is written to a .part file, which is renamed once the recording finished.
+func (dl *Downloader) DownloadLive(ctx context.Context, v *youtube.Video, outputFile string, duration time.Duration) (err error) {
+	dl.logger().Info("Recording live stream", "id", v.ID, "duration", duration)
+
+	if outputFile == "" {
+		outputFile = SanitizeFilename(v.Title) + pickIdealFileExtension("video/mp2t")
+	}
+
+	destFile, err := dl.GetOutputFile(v, nil, outputFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		dl.complete(destFile, err)
+	}()
+
+	// recordings are only moved to the output file once they are finished, so existing ones are complete
+	var skip bool
+	destFile, skip, err = dl.prepareOutput(v, destFile, fileExists)
+	if skip || err != nil {
+		return err
+	}
+
+	stream, err := dl.GetHLSStreamContext(ctx, v, duration)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	// the length of live streams is unknown, so there is no progress bar
+	prog := &progress{
+		updates:   dl.ProgressUpdates,
+		callback:  dl.OnProgress,
+		rateLimit: dl.RateLimit,
+	}
+
+	return dl.downloadPart(v, destFile, func(w io.Writer) error {
+		_, err := dl.copy(io.MultiWriter(w, prog), dl.limitRate(ctx, stream))
+		return err
+	})
 }
 
 // DownloadComposite : Downloads audio and video streams separately and merges them via ffmpeg.
-func (dl *Downloader) DownloadComposite(ctx context.Context, outputFile string, v *youtube.Video, quality string, mimetype, language string) error {
-	videoFormat, audioFormat, err1 := getVideoAudioFormats(v, quality, mimetype, language)
+func (dl *Downloader) DownloadComposite(ctx context.Context, outputFile string, v *youtube.Video, quality string, mimetype, language string) (err error) {
+	videoFormat, audioFormat, err1 := getVideoAudioFormats(v, quality, mimetype, language, dl.codecPreference(), dl.ContainerPreference)
 	if err1 != nil {
 		return err1
 	}
 
-	log := youtube.Logger.With("id", v.ID)
+	return dl.downloadComposite(ctx, outputFile, v, videoFormat, audioFormat)
+}
+
+// downloadComposite downloads the given video and audio formats and merges them via ffmpeg.
+// The merged file is written to a .part file first, so existing output files are complete for SkipExisting.
+func (dl *Downloader) downloadComposite(ctx context.Context, outputFile string, v *youtube.Video, videoFormat, audioFormat *youtube.Format) (err error) {
+	log := dl.logger().With("id", v.ID)
 
 	log.Info(
 		"Downloading composite video",
@@ -74,34 +728,54 @@ func (dl *Downloader) DownloadComposite(ctx context.Context, outputFile string,
 		"audioMimeType", audioFormat.MimeType,
 	)
 
-	destFile, err := dl.getOutputFile(v, videoFormat, outputFile)
+	destFile, err := dl.GetOutputFile(v, videoFormat, outputFile)
 	if err != nil {
 		return err
 	}
-	outputDir := filepath.Dir(destFile)
+	defer func() {
+		dl.complete(destFile, err)
+	}()
+
+	var skip bool
+	destFile, skip, err = dl.prepareOutput(v, destFile, fileExists)
+	if skip || err != nil {
+		return err
+	}
+
+	// fail early instead of after downloading both streams
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return fmt.Errorf("ffmpeg is required to merge video and audio: %w", err)
+	}
+
+	tempDir := filepath.Dir(dl.tempFile(destFile))
+
+	// ffmpeg picks the container by the extension, so it has to stay last
+	ext := filepath.Ext(destFile)
+	partFile := strings.TrimSuffix(dl.tempFile(destFile), ext) + ".part" + ext
+	defer os.Remove(partFile)
 
 	// Create temporary video file
-	videoFile, err := os.CreateTemp(outputDir, "youtube_*.m4v")
+	videoFile, err := os.CreateTemp(tempDir, "youtube_*"+pickIdealFileExtension(videoFormat.MimeType))
 	if err != nil {
 		return err
 	}
 	defer os.Remove(videoFile.Name())
 
 	// Create temporary audio file
-	audioFile, err := os.CreateTemp(outputDir, "youtube_*.m4a")
+	audioFile, err := os.CreateTemp(tempDir, "youtube_*"+pickIdealFileExtension(audioFormat.MimeType))
 	if err != nil {
 		return err
 	}
 	defer os.Remove(audioFile.Name())
 
 	log.Debug("Downloading video file...")
-	err = dl.videoDLWorker(ctx, videoFile, v, videoFormat)
+	err = dl.videoDLWorker(ctx, videoFile, v, videoFormat, 0)
 	if err != nil {
 		return err
 	}
 
 	log.Debug("Downloading audio file...")
-	err = dl.videoDLWorker(ctx, audioFile, v, audioFormat)
+	err = dl.videoDLWorker(ctx, audioFile, v, audioFormat, 0)
 	if err != nil {
 		return err
 	}
@@ -112,17 +786,184 @@ func (dl *Downloader) DownloadComposite(ctx context.Context, outputFile string,
 		"-i", audioFile.Name(),
 		"-c", "copy", // Just copy without re-encoding
 		"-shortest", // Finish encoding when the shortest input stream ends
-		destFile,
+		partFile,
 		"-loglevel", "warning",
 	)
 	ffmpegVersionCmd.Stderr = os.Stderr
 	ffmpegVersionCmd.Stdout = os.Stdout
 	log.Info("merging video and audio", "output", destFile)
 
-	return ffmpegVersionCmd.Run()
+	if err = ffmpegVersionCmd.Run(); err != nil {
+		return err
+	}
+
+	if dl.FileMode != 0 {
+		// ffmpeg creates the file with the default permissions
+		if err = os.Chmod(partFile, dl.FileMode); err != nil {
+			return err
+		}
+	}
+
+	if dl.Hash != nil {
+		info, err := os.Stat(partFile)
+		if err != nil {
+			return err
+		}
+
+		if err := hashFile(dl.Hash, partFile, info.Size()); err != nil {
+			return err
+		}
+	}
+
+	return dl.finish(v, partFile, destFile)
+}
+
+// DownloadCaption : Downloads the caption track of the given language as SubRip subtitles.
+func (dl *Downloader) DownloadCaption(ctx context.Context, v *youtube.Video, languageCode, outputFile string) error {
+	var track *youtube.CaptionTrack
+	for i := range v.CaptionTracks {
+		if v.CaptionTracks[i].LanguageCode == languageCode {
+			track = &v.CaptionTracks[i]
+			break
+		}
+	}
+
+	if track == nil {
+		return fmt.Errorf("no caption track found for language %q", languageCode)
+	}
+
+	dl.logger().Info("Downloading caption", "id", v.ID, "language", languageCode)
+
+	captions, err := dl.GetCaptionsContext(ctx, track)
+	if err != nil {
+		return err
+	}
+
+	if outputFile == "" {
+		outputFile = SanitizeFilename(v.Title) + "." + languageCode + ".srt"
+	}
+
+	destFile, err := dl.GetOutputFile(v, nil, outputFile)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(destFile, []byte(captions.SRT()), dl.fileMode(0o644))
+}
+
+// DownloadChapters : Writes the chapters of the video as WebVTT, or as ffmpeg metadata if outputFile ends with .ffmetadata.
+func (dl *Downloader) DownloadChapters(v *youtube.Video, outputFile string) error {
+	if len(v.Chapters) == 0 {
+		return youtube.ErrNoChapters
+	}
+
+	if outputFile == "" {
+		outputFile = SanitizeFilename(v.Title) + ".chapters.vtt"
+	}
+
+	destFile, err := dl.GetOutputFile(v, nil, outputFile)
+	if err != nil {
+		return err
+	}
+
+	data := v.Chapters.WebVTT()
+	if filepath.Ext(destFile) == ".ffmetadata" {
+		data = v.Chapters.FFMetadata()
+	}
+
+	return os.WriteFile(destFile, []byte(data), dl.fileMode(0o644))
 }
 
-func getVideoAudioFormats(v *youtube.Video, quality string, mimetype, language string) (*youtube.Format, *youtube.Format, error) {
+// DownloadThumbnail : Downloads the thumbnail with the highest resolution.
+func (dl *Downloader) DownloadThumbnail(ctx context.Context, v *youtube.Video, outputFile string) error {
+	thumbnail, ok := v.Thumbnails.Largest()
+	if !ok {
+		return youtube.ErrNoThumbnail
+	}
+
+	dl.logger().Info("Downloading thumbnail", "id", v.ID, "width", thumbnail.Width, "height", thumbnail.Height)
+
+	data, err := dl.GetThumbnailContext(ctx, &thumbnail)
+	if err != nil {
+		return err
+	}
+
+	if outputFile == "" {
+		outputFile = SanitizeFilename(v.Title) + thumbnailExtension(thumbnail.URL)
+	}
+
+	destFile, err := dl.GetOutputFile(v, nil, outputFile)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(destFile, data, dl.fileMode(0o644))
+}
+
+// DownloadStoryboards : Downloads the sprite sheets of the storyboard with the highest resolution into a directory.
+func (dl *Downloader) DownloadStoryboards(ctx context.Context, v *youtube.Video, dir string) error {
+	if len(v.Storyboards) == 0 {
+		return errors.New("no storyboards found")
+	}
+
+	storyboard := &v.Storyboards[len(v.Storyboards)-1]
+
+	dl.logger().Info("Downloading storyboards", "id", v.ID, "width", storyboard.Width, "height", storyboard.Height, "sheets", len(storyboard.URLs))
+
+	if dir == "" {
+		dir = SanitizeFilename(v.Title) + " storyboards"
+	}
+
+	destDir, err := dl.GetOutputFile(v, nil, dir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(destDir, dl.dirMode()); err != nil {
+		return err
+	}
+
+	for i, sheetURL := range storyboard.URLs {
+		data, err := dl.GetStoryboardSheetContext(ctx, storyboard, i)
+		if err != nil {
+			return err
+		}
+
+		name := fmt.Sprintf("%03d%s", i, thumbnailExtension(sheetURL))
+		if err := os.WriteFile(filepath.Join(destDir, name), data, dl.fileMode(0o644)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func thumbnailExtension(thumbnailURL string) string {
+	if uri, err := url.Parse(thumbnailURL); err == nil {
+		if ext := path.Ext(uri.Path); ext != "" {
+			return ext
+		}
+	}
+
+	return ".jpg"
+}
+
+func getAudioOnlyFormat(v *youtube.Video, mimetype string) (*youtube.Format, error) {
+	formats := v.Formats.Kind(youtube.FormatAudioOnly)
+	if mimetype != "" {
+		formats = formats.Type(mimetype)
+	}
+
+	if len(formats) == 0 {
+		return nil, errors.New("no audio format found after filtering")
+	}
+
+	formats.Sort()
+
+	return &formats[0], nil
+}
+
+func getVideoAudioFormats(v *youtube.Video, quality string, mimetype, language string, codecs []string, container string) (*youtube.Format, *youtube.Format, error) {
 	var videoFormats, audioFormats youtube.FormatList
 
 	formats := v.Formats
@@ -149,26 +990,56 @@ func getVideoAudioFormats(v *youtube.Video, quality string, mimetype, language s
 		return nil, nil, errors.New("no audio format found after filtering")
 	}
 
-	videoFormats.Sort()
+	videoFormats.SortByCodecs(codecs...)
 	audioFormats.Sort()
 
+	if container != "" {
+		videoFormats.PreferContainer(container)
+		audioFormats.PreferContainer(container)
+	}
+
 	return &videoFormats[0], &audioFormats[0], nil
 }
 
-func (dl *Downloader) videoDLWorker(ctx context.Context, out *os.File, video *youtube.Video, format *youtube.Format) error {
-	stream, size, err := dl.GetStreamContext(ctx, video, format)
+func (dl *Downloader) videoDLWorker(ctx context.Context, out io.Writer, video *youtube.Video, format *youtube.Format, offset int64) error {
+	var (
+		stream io.ReadCloser
+		size   int64
+		err    error
+	)
+
+	if offset > 0 {
+		stream, size, err = dl.GetStreamRangeContext(ctx, video, format, offset, format.ContentLength-1)
+	} else {
+		stream, size, err = dl.GetStreamContext(ctx, video, format)
+	}
 	if err != nil {
 		return err
 	}
+	defer stream.Close()
+
+	return dl.copyStream(ctx, out, stream, offset, size)
+}
 
+// copyStream copies the stream into out while tracking the progress.
+// The offset is the number of bytes written before, size the length of the stream.
+func (dl *Downloader) copyStream(ctx context.Context, out io.Writer, stream io.Reader, offset, size int64) error {
 	prog := &progress{
-		contentLength: float64(size),
+		contentLength:     offset + size,
+		totalWrittenBytes: offset,
+		updates:           dl.ProgressUpdates,
+		callback:          dl.OnProgress,
+		log:               dl.logger(),
+		rateLimit:         dl.RateLimit,
 	}
 
+	// the size is unknown for compressed or chunked responses
+	unknownSize := prog.contentLength <= 0
+
 	// create progress bar
 	progress := mpb.New(mpb.WithWidth(64))
 	bar := progress.AddBar(
-		int64(prog.contentLength),
+		max(prog.contentLength, 0),
 
 		mpb.PrependDecorators(
 			decor.CountersKibiByte("% .2f / % .2f"),
@@ -180,14 +1051,48 @@ func (dl *Downloader) videoDLWorker(ctx context.Context, out *os.File, video *yo
 			decor.EwmaSpeed(decor.UnitKiB, "% .2f", 60),
 		),
 	)
+	bar.SetCurrent(offset)
+	if unknownSize {
+		// grow the total with the progress instead of completing at once
+		bar.SetTotal(0, false)
+	}
 
-	reader := bar.ProxyReader(stream)
+	reader := bar.ProxyReader(dl.limitRate(ctx, stream))
 	mw := io.MultiWriter(out, prog)
-	_, err = io.Copy(mw, reader)
+	_, err := dl.copy(mw, reader)
+	if err == nil && !unknownSize && prog.totalWrittenBytes != prog.contentLength {
+		err = fmt.Errorf("%w: got %d of %d bytes", ErrIncompleteDownload, prog.totalWrittenBytes, prog.contentLength)
+	}
+
 	if err != nil {
+		// the bar never completes, so it has to be aborted to stop rendering
+		bar.Abort(false)
+		progress.Wait()
 		return err
 	}
 
+	if unknownSize {
+		bar.SetTotal(-1, true)
+	}
+
 	progress.Wait()
 	return nil
 }
+
+// limitRate wraps the stream with a rate limiter if RateLimit is set
+func (dl *Downloader) limitRate(ctx context.Context, stream io.Reader) io.Reader {
+	if dl.RateLimit <= 0 {
+		return stream
+	}
+
+	return newRateLimitedReader(ctx, stream, dl.RateLimit)
+}
+
+// copy copies the stream with a buffer of CopyBufferSize if set
+func (dl *Downloader) copy(dst io.Writer, src io.Reader) (int64, error) {
+	if dl.CopyBufferSize <= 0 {
+		return io.Copy(dst, src)
+	}
+
+	return io.CopyBuffer(dst, src, make([]byte, dl.CopyBufferSize))
+}
@@ -1,9 +1,19 @@
 package downloader
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/stretchr/testify/assert"
@@ -46,6 +56,421 @@ func TestDownload_FirstStream(t *testing.T) {
 	}
 }
 
+func TestHashFile(t *testing.T) {
+	require := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "partial.mp4")
+	require.NoError(os.WriteFile(path, []byte("hello world"), 0o600))
+
+	dl := Downloader{Hash: sha256.New()}
+	require.NoError(hashFile(dl.Hash, path, 5))
+
+	// sha256 of "hello"
+	require.Equal("2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", dl.Checksum())
+}
+
+func TestDownload_ExpectedChecksum(t *testing.T) {
+	require := require.New(t)
+
+	dl := Downloader{OutputDir: t.TempDir(), Resume: true, Hash: sha256.New()}
+	video := &youtube.Video{Title: "hello"}
+	format := &youtube.Format{MimeType: "video/mp4", ContentLength: 5}
+	partFile := filepath.Join(dl.OutputDir, "hello.mp4.part")
+
+	// the hash of a previous download must not leak into this one
+	dl.Hash.Write([]byte("previous"))
+
+	dl.ExpectedChecksum = "0000"
+	require.NoError(os.WriteFile(partFile, []byte("hello"), 0o600))
+	require.ErrorIs(dl.Download(context.Background(), video, format, ""), ErrChecksumMismatch)
+	require.NoFileExists(partFile)
+	require.NoFileExists(filepath.Join(dl.OutputDir, "hello.mp4"))
+
+	// sha256 of "hello"
+	dl.ExpectedChecksum = "2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824"
+	require.NoError(os.WriteFile(partFile, []byte("hello"), 0o600))
+	require.NoError(dl.Download(context.Background(), video, format, ""))
+	require.FileExists(filepath.Join(dl.OutputDir, "hello.mp4"))
+}
+
+func TestGetOutputFile(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	dl := Downloader{OutputDir: filepath.Join(dir, "videos")}
+	video := &youtube.Video{Title: "Some: title?"}
+	format := &youtube.Format{MimeType: `video/webm; codecs="vp9"`}
+
+	path, err := dl.GetOutputFile(video, format, "")
+	require.NoError(err)
+	require.Equal(filepath.Join(dir, "videos", "Some title.webm"), path)
+	require.DirExists(dl.OutputDir)
+
+	path, err = dl.GetOutputFile(video, format, "custom.mkv")
+	require.NoError(err)
+	require.Equal(filepath.Join(dir, "videos", "custom.mkv"), path)
+}
+
+func TestGetOutputFile_Template(t *testing.T) {
+	require := require.New(t)
+
+	dl := Downloader{
+		OutputDir:        t.TempDir(),
+		FilenameTemplate: "{{.Author}}/{{.Title}} [{{.ID}}] {{.Quality}}.{{.Ext}}",
+	}
+	video := &youtube.Video{ID: "BaW_jenozKc", Title: "What? A/B test", Author: "../Someone"}
+	format := &youtube.Format{MimeType: `audio/mp4; codecs="mp4a.40.2"`, Quality: "tiny"}
+
+	path, err := dl.GetOutputFile(video, format, "")
+	require.NoError(err)
+	require.Equal(filepath.Join(dl.OutputDir, "Someone", "What AB test [BaW_jenozKc] tiny.m4a"), path)
+	require.DirExists(filepath.Dir(path))
+
+	dl.FilenameTemplate = "{{.Missing}}"
+	_, err = dl.GetOutputFile(video, format, "")
+	require.Error(err)
+}
+
+func TestDownloadLive_OnComplete(t *testing.T) {
+	require := require.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "#EXTM3U\n#EXTINF:2,\nsegment.ts\n#EXT-X-ENDLIST\n")
+	})
+	mux.HandleFunc("/segment.ts", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "segment")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var completed []string
+	dl := Downloader{
+		OutputDir: t.TempDir(),
+		OnComplete: func(path string, err error) {
+			require.NoError(err)
+			completed = append(completed, path)
+		},
+	}
+	video := &youtube.Video{Title: "live", HLSManifestURL: server.URL + "/index.m3u8"}
+
+	require.NoError(dl.DownloadLive(context.Background(), video, "", 0))
+	require.Equal([]string{filepath.Join(dl.OutputDir, "live.ts")}, completed)
+
+	data, err := os.ReadFile(completed[0])
+	require.NoError(err)
+	require.Equal("segment", string(data))
+}
+
+func TestDownloadLive_PartFile(t *testing.T) {
+	require := require.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "#EXTM3U\n#EXTINF:2,\nsegment.ts\n#EXT-X-ENDLIST\n")
+	})
+	mux.HandleFunc("/segment.ts", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	dl := Downloader{OutputDir: t.TempDir(), KeepPartial: true}
+	video := &youtube.Video{Title: "live", HLSManifestURL: server.URL + "/index.m3u8"}
+
+	// an interrupted recording doesn't look like a finished one
+	path := filepath.Join(dl.OutputDir, "live.ts")
+	require.NoError(os.WriteFile(path, []byte("previous"), 0o600))
+	require.Error(dl.DownloadLive(context.Background(), video, "", 0))
+
+	previous, err := os.ReadFile(path)
+	require.NoError(err)
+	require.Equal("previous", string(previous))
+	require.FileExists(path + ".part")
+
+	// finished recordings are skipped
+	dl.SkipExisting = true
+	require.NoError(dl.DownloadLive(context.Background(), video, "", 0))
+}
+
+func TestDownloadPreview(t *testing.T) {
+	require := require.New(t)
+
+	data := bytes.Repeat([]byte("0123456789"), 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var start, end int
+		_, err := fmt.Sscanf(r.URL.Query().Get("range"), "%d-%d", &start, &end)
+		assert.NoError(t, err)
+		w.Write(data[start : end+1])
+	}))
+	defer server.Close()
+
+	dl := Downloader{OutputDir: t.TempDir()}
+	video := &youtube.Video{Title: "preview"}
+	format := &youtube.Format{URL: server.URL, MimeType: "video/mp4", ContentLength: 1000, ApproxDurationMs: "10000"}
+
+	require.NoError(dl.DownloadPreview(context.Background(), video, format, "", 2*time.Second))
+
+	preview, err := os.ReadFile(filepath.Join(dl.OutputDir, "preview.mp4"))
+	require.NoError(err)
+	require.Equal(data[:200], preview)
+
+	require.Error(dl.DownloadPreview(context.Background(), video, format, "", 0))
+}
+
+func TestDownloadRange_PartFile(t *testing.T) {
+	require := require.New(t)
+
+	data := []byte("0123456789")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var start, end int
+		_, err := fmt.Sscanf(r.URL.Query().Get("range"), "%d-%d", &start, &end)
+		assert.NoError(t, err)
+		w.Write(data[start : end+1])
+	}))
+	defer server.Close()
+
+	dl := Downloader{OutputDir: t.TempDir(), OnCollision: CollisionAppendNumber}
+	video := &youtube.Video{Title: "clip"}
+	format := &youtube.Format{URL: server.URL, MimeType: "video/mp4", ContentLength: 10}
+
+	// an existing complete file isn't overwritten by the clip
+	path := filepath.Join(dl.OutputDir, "clip.mp4")
+	require.NoError(os.WriteFile(path, data, 0o600))
+
+	require.NoError(dl.DownloadRange(context.Background(), video, format, "", 2, 5))
+
+	existing, err := os.ReadFile(path)
+	require.NoError(err)
+	require.Equal(data, existing)
+
+	clip, err := os.ReadFile(filepath.Join(dl.OutputDir, "clip (1).mp4"))
+	require.NoError(err)
+	require.Equal("2345", string(clip))
+	require.NoFileExists(filepath.Join(dl.OutputDir, "clip (1).mp4.part"))
+
+	// a clip of the same size is skipped without a request
+	server.Close()
+	dl.SkipExisting = true
+	require.NoError(dl.DownloadRange(context.Background(), video, format, "clip (1).mp4", 2, 5))
+	require.Equal(int64(4), rangeSize(format, 2, 5))
+	require.Equal(int64(3), rangeSize(format, 7, 20))
+}
+
+func TestDownloadChapters(t *testing.T) {
+	require := require.New(t)
+
+	dl := Downloader{OutputDir: t.TempDir()}
+	video := &youtube.Video{Title: "podcast"}
+	require.ErrorIs(dl.DownloadChapters(video, ""), youtube.ErrNoChapters)
+
+	video.Chapters = youtube.Chapters{
+		{Start: 0, End: time.Minute, Title: "Intro"},
+		{Start: time.Minute, End: 2 * time.Minute, Title: "Outro"},
+	}
+	require.NoError(dl.DownloadChapters(video, ""))
+	require.FileExists(filepath.Join(dl.OutputDir, "podcast.chapters.vtt"))
+
+	require.NoError(dl.DownloadChapters(video, "podcast.ffmetadata"))
+	data, err := os.ReadFile(filepath.Join(dl.OutputDir, "podcast.ffmetadata"))
+	require.NoError(err)
+	require.Equal(video.Chapters.FFMetadata(), string(data))
+}
+
+func TestDownloader_FileMode(t *testing.T) {
+	require := require.New(t)
+
+	dl := Downloader{OutputDir: filepath.Join(t.TempDir(), "private"), FileMode: 0o600, DirMode: 0o700}
+	video := &youtube.Video{Title: "private", Chapters: youtube.Chapters{{Start: 0, End: time.Minute, Title: "Intro"}}}
+
+	require.NoError(dl.DownloadChapters(video, ""))
+
+	info, err := os.Stat(dl.OutputDir)
+	require.NoError(err)
+	require.Equal(os.FileMode(0o700), info.Mode().Perm())
+
+	info, err = os.Stat(filepath.Join(dl.OutputDir, "private.chapters.vtt"))
+	require.NoError(err)
+	require.Equal(os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestDownload_SkipExisting(t *testing.T) {
+	require := require.New(t)
+
+	dl := Downloader{OutputDir: t.TempDir(), SkipExisting: true}
+	video := &youtube.Video{Title: "existing"}
+	format := &youtube.Format{MimeType: "video/mp4", ContentLength: 5}
+
+	path := filepath.Join(dl.OutputDir, "existing.mp4")
+	require.NoError(os.WriteFile(path, []byte("video"), 0o600))
+
+	// no request is made as the file is complete
+	require.NoError(dl.Download(context.Background(), video, format, ""))
+	require.True(isComplete(path, format))
+	require.False(isComplete(path, &youtube.Format{ContentLength: 6}))
+}
+
+func TestDownload_SkipExistingHashesFile(t *testing.T) {
+	require := require.New(t)
+
+	dl := Downloader{OutputDir: t.TempDir(), SkipExisting: true, Hash: sha256.New(), WriteInfoJSON: true}
+	video := &youtube.Video{ID: "BaW_jenozKc", Title: "hello"}
+	format := &youtube.Format{MimeType: "video/mp4", ContentLength: 5}
+
+	path := filepath.Join(dl.OutputDir, "hello.mp4")
+	require.NoError(os.WriteFile(path, []byte("hello"), 0o600))
+
+	dl.Hash.Write([]byte("previous"))
+	require.NoError(dl.Download(context.Background(), video, format, ""))
+
+	// sha256 of "hello"
+	require.Equal("2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", dl.Checksum())
+	require.FileExists(path + ".info.json")
+
+	dl.ExpectedChecksum = "0000"
+	require.ErrorIs(dl.Download(context.Background(), video, format, ""), ErrChecksumMismatch)
+}
+
+func TestDownloadComposite_SkipExisting(t *testing.T) {
+	require := require.New(t)
+
+	dl := Downloader{OutputDir: t.TempDir(), SkipExisting: true, WriteInfoJSON: true}
+	video := &youtube.Video{ID: "BaW_jenozKc", Title: "merged"}
+	videoFormat := &youtube.Format{ItagNo: 136, MimeType: `video/mp4; codecs="avc1.4d401f"`, URL: "http://127.0.0.1:0/136"}
+	audioFormat := &youtube.Format{ItagNo: 140, MimeType: `audio/mp4; codecs="mp4a.40.2"`, URL: "http://127.0.0.1:0/140"}
+
+	path := filepath.Join(dl.OutputDir, "merged.mp4")
+	require.NoError(os.WriteFile(path, []byte("merged"), 0o600))
+
+	// neither the streams nor ffmpeg are needed for an existing merged file
+	require.NoError(dl.downloadComposite(context.Background(), "", video, videoFormat, audioFormat))
+	require.FileExists(path + ".info.json")
+}
+
+func TestDownloader_resolveCollision(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "title.mp4")
+
+	dl := Downloader{OnCollision: CollisionAppendNumber}
+	resolved, skip := dl.resolveCollision(path)
+	require.Equal(path, resolved)
+	require.False(skip)
+
+	require.NoError(os.WriteFile(path, []byte("video"), 0o600))
+	require.NoError(os.WriteFile(filepath.Join(dir, "title (1).mp4"), []byte("video"), 0o600))
+
+	resolved, skip = dl.resolveCollision(path)
+	require.Equal(filepath.Join(dir, "title (2).mp4"), resolved)
+	require.False(skip)
+
+	dl.OnCollision = CollisionSkip
+	resolved, skip = dl.resolveCollision(path)
+	require.Equal(path, resolved)
+	require.True(skip)
+
+	dl.OnCollision = CollisionOverwrite
+	resolved, skip = dl.resolveCollision(path)
+	require.Equal(path, resolved)
+	require.False(skip)
+}
+
+func TestDownload_ResumeCompletePart(t *testing.T) {
+	require := require.New(t)
+
+	dl := Downloader{OutputDir: t.TempDir(), Resume: true}
+	video := &youtube.Video{Title: "resumed"}
+	format := &youtube.Format{MimeType: "video/mp4", ContentLength: 5}
+
+	path := filepath.Join(dl.OutputDir, "resumed.mp4")
+	require.NoError(os.WriteFile(path+".part", []byte("video"), 0o600))
+
+	// no request is made as the part file is complete
+	require.NoError(dl.Download(context.Background(), video, format, ""))
+	require.FileExists(path)
+	require.NoFileExists(path + ".part")
+}
+
+func TestDownload_TempDir(t *testing.T) {
+	require := require.New(t)
+
+	dl := Downloader{OutputDir: t.TempDir(), TempDir: t.TempDir(), Resume: true}
+	video := &youtube.Video{Title: "temp"}
+	format := &youtube.Format{MimeType: "video/mp4", ContentLength: 5}
+
+	require.NoError(os.WriteFile(filepath.Join(dl.TempDir, "temp.mp4.part"), []byte("video"), 0o600))
+
+	// the complete part file is moved from the temporary directory
+	require.NoError(dl.Download(context.Background(), video, format, ""))
+	require.FileExists(filepath.Join(dl.OutputDir, "temp.mp4"))
+	require.NoFileExists(filepath.Join(dl.TempDir, "temp.mp4.part"))
+}
+
+func TestDownload_WriteInfoJSON(t *testing.T) {
+	require := require.New(t)
+
+	dl := Downloader{OutputDir: t.TempDir(), Resume: true, WriteInfoJSON: true}
+	format := youtube.Format{ItagNo: 18, MimeType: "video/mp4", ContentLength: 5}
+	video := &youtube.Video{
+		ID:       "BaW_jenozKc",
+		Title:    "info",
+		Duration: 10 * time.Second,
+		Chapters: youtube.Chapters{{Start: 0, End: 10 * time.Second, Title: "Intro"}},
+		Formats:  youtube.FormatList{format},
+	}
+
+	require.NoError(os.WriteFile(filepath.Join(dl.OutputDir, "info.mp4.part"), []byte("video"), 0o600))
+	require.NoError(dl.Download(context.Background(), video, &format, ""))
+
+	data, err := os.ReadFile(filepath.Join(dl.OutputDir, "info.mp4.info.json"))
+	require.NoError(err)
+
+	var info InfoJSON
+	require.NoError(json.Unmarshal(data, &info))
+	require.Equal("BaW_jenozKc", info.ID)
+	require.Equal(float64(10), info.Duration)
+	require.Equal([]InfoChapter{{Start: 0, End: 10, Title: "Intro"}}, info.Chapters)
+	require.Equal([]InfoFormat{{Itag: 18, MimeType: "video/mp4", ContentLength: 5}}, info.Formats)
+	require.Nil(info.PublishDate)
+}
+
+func TestGetAdaptivePairs(t *testing.T) {
+	video := &youtube.Video{Formats: youtube.FormatList{
+		{ItagNo: 18, MimeType: `video/mp4; codecs="avc1.42001E, mp4a.40.2"`, Width: 640, Height: 360, AudioChannels: 2},
+		{ItagNo: 136, MimeType: `video/mp4; codecs="avc1.4d401f"`, Width: 1280, Height: 720},
+		{ItagNo: 247, MimeType: `video/webm; codecs="vp9"`, Width: 1280, Height: 720},
+		{ItagNo: 248, MimeType: `video/webm; codecs="vp9"`, Width: 1920, Height: 1080},
+		{ItagNo: 140, MimeType: `audio/mp4; codecs="mp4a.40.2"`, AudioChannels: 2, Bitrate: 130000},
+		{ItagNo: 251, MimeType: `audio/webm; codecs="opus"`, AudioChannels: 2, Bitrate: 140000},
+	}}
+
+	pairs := getAdaptivePairs(video, 720, []string{"h264"}, "")
+	require.Len(t, pairs, 2)
+
+	// the audio matches the container of the video
+	assert.Equal(t, [2]int{136, 140}, [2]int{pairs[0][0].ItagNo, pairs[0][1].ItagNo})
+	assert.Equal(t, [2]int{247, 251}, [2]int{pairs[1][0].ItagNo, pairs[1][1].ItagNo})
+}
+
+func TestDownloadBestUnder_Progressive(t *testing.T) {
+	require := require.New(t)
+
+	dl := Downloader{OutputDir: t.TempDir(), Resume: true}
+	video := &youtube.Video{Title: "best", Formats: youtube.FormatList{
+		{ItagNo: 18, MimeType: "video/mp4", Width: 640, Height: 360, AudioChannels: 2, ContentLength: 5},
+		{ItagNo: 22, MimeType: "video/mp4", Width: 1280, Height: 720, AudioChannels: 2, ContentLength: 5},
+	}}
+
+	require.NoError(os.WriteFile(filepath.Join(dl.OutputDir, "best.mp4.part"), []byte("video"), 0o600))
+
+	// 720p exceeds the limit, the complete part file of 360p is used
+	require.NoError(dl.DownloadBestUnder(context.Background(), video, "", 480))
+	require.FileExists(filepath.Join(dl.OutputDir, "best.mp4"))
+
+	require.EqualError(dl.DownloadBestUnder(context.Background(), video, "", 240), "no format with video and audio found up to 240p")
+}
+
 func TestYoutube_DownloadWithHighQualityFails(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -101,7 +526,7 @@ func Test_getVideoAudioFormats(t *testing.T) {
 		{ItagNo: 249, MimeType: "audio/webm; codecs=\"opus\"", Quality: "tiny", Bitrate: 72862, FPS: 0, Width: 0, Height: 0, LastModified: "1540474783513282", ContentLength: 24839529, QualityLabel: "", ProjectionType: "RECTANGULAR", AverageBitrate: 55914, AudioQuality: "AUDIO_QUALITY_LOW", ApproxDurationMs: "3553941", AudioSampleRate: "48000", AudioChannels: 2},
 	}}
 	{
-		videoFormat, audioFormat, err := getVideoAudioFormats(v, "hd720", "mp4", "")
+		videoFormat, audioFormat, err := getVideoAudioFormats(v, "hd720", "mp4", "", nil, "")
 		require.NoError(err)
 		require.NotNil(videoFormat)
 		require.Equal(398, videoFormat.ItagNo)
@@ -110,11 +535,107 @@ func Test_getVideoAudioFormats(t *testing.T) {
 	}
 
 	{
-		videoFormat, audioFormat, err := getVideoAudioFormats(v, "large", "webm", "")
+		videoFormat, audioFormat, err := getVideoAudioFormats(v, "large", "webm", "", nil, "")
 		require.NoError(err)
 		require.NotNil(videoFormat)
 		require.Equal(244, videoFormat.ItagNo)
 		require.NotNil(audioFormat)
 		require.Equal(251, audioFormat.ItagNo)
 	}
+	{
+		videoFormat, _, err := getVideoAudioFormats(v, "hd720", "mp4", "", []string{"h264"}, "")
+		require.NoError(err)
+		require.NotNil(videoFormat)
+		require.Equal(136, videoFormat.ItagNo)
+	}
+	{
+		// the downloader prefers h264 by default
+		videoFormat, _, err := getVideoAudioFormats(v, "hd720", "mp4", "", (&Downloader{}).codecPreference(), "")
+		require.NoError(err)
+		require.Equal(136, videoFormat.ItagNo)
+
+		videoFormat, _, err = getVideoAudioFormats(v, "hd720", "mp4", "", (&Downloader{CodecPreference: []string{"av1"}}).codecPreference(), "")
+		require.NoError(err)
+		require.Equal(398, videoFormat.ItagNo)
+	}
+	{
+		videoFormat, audioFormat, err := getVideoAudioFormats(v, "large", "", "", nil, "webm")
+		require.NoError(err)
+		require.Equal(244, videoFormat.ItagNo)
+		require.Equal(251, audioFormat.ItagNo)
+	}
+}
+
+func Test_getAudioOnlyFormat(t *testing.T) {
+	require := require.New(t)
+
+	v := &youtube.Video{Formats: []youtube.Format{
+		{ItagNo: 18, MimeType: `video/mp4; codecs="avc1.42001E, mp4a.40.2"`, Width: 640, Height: 360, AudioChannels: 2},
+		{ItagNo: 140, MimeType: `audio/mp4; codecs="mp4a.40.2"`, Bitrate: 133909, AudioChannels: 2},
+		{ItagNo: 250, MimeType: `audio/webm; codecs="opus"`, Bitrate: 92040, AudioChannels: 2},
+		{ItagNo: 251, MimeType: `audio/webm; codecs="opus"`, Bitrate: 168872, AudioChannels: 2},
+	}}
+
+	format, err := getAudioOnlyFormat(v, "")
+	require.NoError(err)
+	require.Equal(140, format.ItagNo)
+
+	format, err = getAudioOnlyFormat(v, "opus")
+	require.NoError(err)
+	require.Equal(251, format.ItagNo)
+
+	_, err = getAudioOnlyFormat(v, "flac")
+	require.Error(err)
+}
+
+func TestDownloader_copy(t *testing.T) {
+	require := require.New(t)
+
+	data := bytes.Repeat([]byte("video"), 1000)
+
+	for _, size := range []int{0, 7, 1 << 20} {
+		var out bytes.Buffer
+		dl := Downloader{CopyBufferSize: size}
+
+		// hide ReadFrom and WriteTo, so the buffer is used
+		n, err := dl.copy(struct{ io.Writer }{&out}, struct{ io.Reader }{bytes.NewReader(data)})
+		require.NoError(err)
+		require.EqualValues(len(data), n)
+		require.Equal(data, out.Bytes())
+	}
+}
+
+func TestDownloader_copyStreamUnknownSize(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		out     bytes.Buffer
+		updates []Progress
+	)
+	dl := Downloader{OnProgress: func(p Progress) { updates = append(updates, p) }}
+
+	require.NoError(dl.copyStream(context.Background(), &out, bytes.NewReader([]byte("video")), 0, -1))
+	require.Equal("video", out.String())
+	require.NotEmpty(updates)
+	require.EqualValues(-1, updates[len(updates)-1].Total)
+	require.EqualValues(5, updates[len(updates)-1].Downloaded)
+}
+
+func TestDownloader_copyStreamIncomplete(t *testing.T) {
+	var out bytes.Buffer
+	dl := Downloader{}
+
+	err := dl.copyStream(context.Background(), &out, bytes.NewReader([]byte("vid")), 0, 5)
+	require.ErrorIs(t, err, ErrIncompleteDownload)
+	require.EqualError(t, err, "incomplete download: got 3 of 5 bytes")
+}
+
+func TestDownloader_copyStreamFails(t *testing.T) {
+	var out bytes.Buffer
+	dl := Downloader{}
+	failure := errors.New("connection reset")
+
+	// returns once the aborted bar stopped rendering
+	err := dl.copyStream(context.Background(), &out, io.MultiReader(bytes.NewReader([]byte("vid")), iotest.ErrReader(failure)), 0, 5)
+	require.ErrorIs(t, err, failure)
 }
@@ -2,7 +2,13 @@ package downloader
 
 import (
 	"mime"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/kkdai/youtube/v2"
 )
 
 const defaultExtension = ".mov"
@@ -27,14 +33,26 @@ var canonicals = map[string]string{
 	"video/mp4":        ".mp4",
 	"video/ogg":        ".ogv",
 	"video/mp2t":       ".ts",
+	"audio/mp4":        ".m4a",
+	"audio/webm":       ".weba",
 }
 
+// audioCodecs are the prefixes of codecs which only contain audio
+var audioCodecs = []string{"mp4a", "opus", "vorbis", "ac-3", "ec-3", "flac"}
+
+// pickIdealFileExtension returns the extension for a mime type like `audio/mp4; codecs="mp4a.40.2"`.
+// Streams with audio codecs only get the extension of the audio type, as the container differs.
+// It is used for all files of streams, so the extensions are consistent.
 func pickIdealFileExtension(mediaType string) string {
-	mediaType, _, err := mime.ParseMediaType(mediaType)
+	mediaType, params, err := mime.ParseMediaType(mediaType)
 	if err != nil {
 		return defaultExtension
 	}
 
+	if subtype, ok := strings.CutPrefix(mediaType, "video/"); ok && isAudioOnly(params["codecs"]) {
+		mediaType = "audio/" + subtype
+	}
+
 	if extension, ok := canonicals[mediaType]; ok {
 		return extension
 	}
@@ -48,6 +66,22 @@ func pickIdealFileExtension(mediaType string) string {
 	return extensions[0]
 }
 
+// isAudioOnly checks whether the codecs parameter of a mime type only lists audio codecs
+func isAudioOnly(codecs string) bool {
+	if codecs == "" {
+		return false
+	}
+
+	for _, codec := range strings.Split(codecs, ",") {
+		codec = strings.TrimSpace(codec)
+		if !slices.ContainsFunc(audioCodecs, func(prefix string) bool { return strings.HasPrefix(codec, prefix) }) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func SanitizeFilename(fileName string) string {
 	// Characters not allowed on mac
 	//	:/
@@ -61,5 +95,50 @@ func SanitizeFilename(fileName string) string {
 	fileName = regexp.MustCompile(`[:/<>\:"\\|?*]`).ReplaceAllString(fileName, "")
 	fileName = regexp.MustCompile(`\s+`).ReplaceAllString(fileName, " ")
 
-	return fileName
+	// Windows rejects names ending with a dot or space
+	return strings.Trim(fileName, " .")
+}
+
+// filenameData holds the fields of FilenameTemplate
+type filenameData struct {
+	ID      string
+	Title   string
+	Author  string
+	Quality string
+	Ext     string // extension without the leading dot
+}
+
+// renderFilename executes the template and sanitizes the fields and every segment of the resulting path
+func renderFilename(text string, v *youtube.Video, format *youtube.Format) (string, error) {
+	tmpl, err := template.New("filename").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	// sanitize the fields, so slashes in titles don't create directories
+	data := filenameData{
+		ID:     SanitizeFilename(v.ID),
+		Title:  SanitizeFilename(v.Title),
+		Author: SanitizeFilename(v.Author),
+	}
+
+	if format != nil {
+		data.Quality = format.QualityLabel
+		if data.Quality == "" {
+			data.Quality = format.Quality
+		}
+		data.Ext = strings.TrimPrefix(pickIdealFileExtension(format.MimeType), ".")
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+
+	segments := strings.Split(filepath.ToSlash(sb.String()), "/")
+	for i := range segments {
+		segments[i] = SanitizeFilename(segments[i])
+	}
+
+	return filepath.Join(segments...), nil
 }
@@ -23,3 +23,48 @@ func TestSanitizeFilename(t *testing.T) {
 		t.Error("The common harmless symbols should remain valid")
 	}
 }
+
+func TestSanitizeFilename_Whitespace(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"tab\tseparated", "tab separated"},
+		{"multi\nline\r\ntitle", "multi line title"},
+		{"long      run  of   spaces", "long run of spaces"},
+		{"  leading and trailing  ", "leading and trailing"},
+		{"trailing dots...", "trailing dots"},
+		{". hidden .", "hidden"},
+		{"emoji 🎬 title ✨", "emoji 🎬 title ✨"},
+	}
+	for _, tt := range tests {
+		if sanitized := SanitizeFilename(tt.input); sanitized != tt.expected {
+			t.Errorf("SanitizeFilename(%q) = %q, want %q", tt.input, sanitized, tt.expected)
+		}
+	}
+}
+
+func TestPickIdealFileExtension(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		expected string
+	}{
+		{`video/mp4; codecs="avc1.42001E, mp4a.40.2"`, ".mp4"},
+		{`video/webm; codecs="vp9"`, ".webm"},
+		{`audio/mp4; codecs="mp4a.40.2"`, ".m4a"},
+		{`audio/webm; codecs="opus"`, ".weba"},
+		{`video/mp4; codecs="av01.0.08M.08"`, ".mp4"},
+		{`video/webm; codecs="vp09.00.40.08"`, ".webm"},
+		{`video/3gpp; codecs="mp4v.20.3, mp4a.40.2"`, ".3gp"},
+		{`audio/mp4; codecs="ec-3"`, ".m4a"},
+		{`video/mp4; codecs="mp4a.40.2"`, ".m4a"},
+		{`video/webm; codecs="opus"`, ".weba"},
+		{"video/mp2t", ".ts"},
+		{"invalid; ;", defaultExtension},
+	}
+	for _, tt := range tests {
+		if got := pickIdealFileExtension(tt.mimeType); got != tt.expected {
+			t.Errorf("pickIdealFileExtension(%q) = %q, want %q", tt.mimeType, got, tt.expected)
+		}
+	}
+}
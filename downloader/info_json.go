@@ -0,0 +1,137 @@
+package downloader
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/kkdai/youtube/v2"
+)
+
+// InfoJSON is the metadata written by WriteInfoJSON to destFile + ".info.json".
+// Fields are only added to it, so archives can rely on the schema.
+// Durations are in seconds, dates in RFC 3339 and omitted if unknown.
+type InfoJSON struct {
+	ID            string          `json:"id"`
+	Title         string          `json:"title"`
+	Description   string          `json:"description"`
+	Author        string          `json:"author"`
+	ChannelID     string          `json:"channel_id"`
+	ChannelHandle string          `json:"channel_handle,omitempty"`
+	Duration      float64         `json:"duration"`
+	Views         int             `json:"views"`
+	Keywords      []string        `json:"keywords"`
+	Category      string          `json:"category,omitempty"`
+	PublishDate   *time.Time      `json:"publish_date,omitempty"`
+	UploadDate    *time.Time      `json:"upload_date,omitempty"`
+	IsLive        bool            `json:"is_live"`
+	Thumbnails    []InfoThumbnail `json:"thumbnails"`
+	Chapters      []InfoChapter   `json:"chapters"`
+	Formats       []InfoFormat    `json:"formats"`
+	Captions      []InfoCaption   `json:"captions"`
+}
+
+// InfoThumbnail is a thumbnail of InfoJSON
+type InfoThumbnail struct {
+	URL    string `json:"url"`
+	Width  uint   `json:"width"`
+	Height uint   `json:"height"`
+}
+
+// InfoChapter is a chapter of InfoJSON with its bounds in seconds
+type InfoChapter struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Title string  `json:"title"`
+}
+
+// InfoFormat is a format of InfoJSON
+type InfoFormat struct {
+	Itag          int    `json:"itag"`
+	MimeType      string `json:"mime_type"`
+	Quality       string `json:"quality"`
+	QualityLabel  string `json:"quality_label,omitempty"`
+	Width         int    `json:"width,omitempty"`
+	Height        int    `json:"height,omitempty"`
+	FPS           int    `json:"fps,omitempty"`
+	Bitrate       int    `json:"bitrate"`
+	ContentLength int64  `json:"content_length,omitempty"`
+	AudioChannels int    `json:"audio_channels,omitempty"`
+}
+
+// InfoCaption is a caption track of InfoJSON
+type InfoCaption struct {
+	LanguageCode string `json:"language_code"`
+	Name         string `json:"name"`
+}
+
+// newInfoJSON collects the metadata of a video
+func newInfoJSON(v *youtube.Video) InfoJSON {
+	info := InfoJSON{
+		ID:            v.ID,
+		Title:         v.Title,
+		Description:   v.Description,
+		Author:        v.Author,
+		ChannelID:     v.ChannelID,
+		ChannelHandle: v.ChannelHandle,
+		Duration:      v.Duration.Seconds(),
+		Views:         v.Views,
+		Keywords:      v.Keywords,
+		Category:      v.Category,
+		IsLive:        v.IsLive,
+		Thumbnails:    []InfoThumbnail{},
+		Chapters:      []InfoChapter{},
+		Formats:       []InfoFormat{},
+		Captions:      []InfoCaption{},
+	}
+
+	if !v.PublishDate.IsZero() {
+		info.PublishDate = &v.PublishDate
+	}
+	if !v.UploadDate.IsZero() {
+		info.UploadDate = &v.UploadDate
+	}
+
+	for _, thumbnail := range v.Thumbnails {
+		info.Thumbnails = append(info.Thumbnails, InfoThumbnail{URL: thumbnail.URL, Width: thumbnail.Width, Height: thumbnail.Height})
+	}
+
+	for _, chapter := range v.Chapters {
+		info.Chapters = append(info.Chapters, InfoChapter{Start: chapter.Start.Seconds(), End: chapter.End.Seconds(), Title: chapter.Title})
+	}
+
+	for _, format := range v.Formats {
+		info.Formats = append(info.Formats, InfoFormat{
+			Itag:          format.ItagNo,
+			MimeType:      format.MimeType,
+			Quality:       format.Quality,
+			QualityLabel:  format.QualityLabel,
+			Width:         format.Width,
+			Height:        format.Height,
+			FPS:           format.FPS,
+			Bitrate:       format.Bitrate,
+			ContentLength: format.ContentLength,
+			AudioChannels: format.AudioChannels,
+		})
+	}
+
+	for _, track := range v.CaptionTracks {
+		info.Captions = append(info.Captions, InfoCaption{LanguageCode: track.LanguageCode, Name: track.Name.SimpleText})
+	}
+
+	return info
+}
+
+// writeInfoJSON writes the metadata of the video next to the downloaded file if WriteInfoJSON is set
+func (dl *Downloader) writeInfoJSON(v *youtube.Video, destFile string) error {
+	if !dl.WriteInfoJSON {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(newInfoJSON(v), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(destFile+".info.json", data, dl.fileMode(0o644))
+}
@@ -1,17 +1,141 @@
 package downloader
 
+import (
+	"log/slog"
+	"time"
+)
+
+const (
+	// speedWindow is the period of the recent throughput which Speed is computed of
+	speedWindow = 5 * time.Second
+
+	// sampleInterval limits the number of samples within the window
+	sampleInterval = 100 * time.Millisecond
+
+	// throttledSpeed is the speed in bytes per second below which a download over the whole window
+	// is considered throttled, which YouTube does for URLs with an untransformed n parameter
+	throttledSpeed = 100 * 1024
+)
+
+// Progress is a snapshot of a running download.
+type Progress struct {
+	Downloaded int64         // number of bytes written so far
+	Total      int64         // expected number of bytes, -1 if unknown
+	Percent    float64       // between 0 and 100, -1 if the total is unknown
+	Speed      float64       // bytes per second over the last seconds
+	ETA        time.Duration // estimated remaining time, -1 if unknown
+	Throttled  bool          // whether the speed stayed below 100 KiB/s over the last seconds, never if RateLimit is lower
+}
+
 type progress struct {
-	contentLength     float64
-	totalWrittenBytes float64
-	downloadLevel     float64
+	contentLength     int64
+	totalWrittenBytes int64
+	updates           chan<- Progress
+	callback          func(Progress)
+	samples           []progressSample
+	log               *slog.Logger // optionally warns once if the download is throttled
+	warned            bool
+	rateLimit         int64 // RateLimit of the downloader, a lower limit than throttledSpeed disables the detection
+}
+
+type progressSample struct {
+	at      time.Time
+	written int64
 }
 
 func (dl *progress) Write(p []byte) (n int, err error) {
 	n = len(p)
-	dl.totalWrittenBytes = dl.totalWrittenBytes + float64(n)
-	currentPercent := (dl.totalWrittenBytes / dl.contentLength) * 100
-	if (dl.downloadLevel <= currentPercent) && (dl.downloadLevel < 100) {
-		dl.downloadLevel++
+	dl.totalWrittenBytes += int64(n)
+	dl.addSample(time.Now())
+
+	if dl.log != nil && !dl.warned && dl.throttled() {
+		dl.warned = true
+		dl.log.Warn("Download is throttled, the stream URL may have an invalid n parameter", "speed", int(dl.speed()))
 	}
+
+	if dl.callback != nil {
+		dl.callback(dl.current())
+	}
+
+	if dl.updates != nil {
+		select {
+		case dl.updates <- dl.current():
+		default:
+			// drop the update if the receiver isn't ready
+		}
+	}
+
 	return
 }
+
+// addSample records the written bytes and drops the samples which are older than the window
+func (dl *progress) addSample(now time.Time) {
+	if last := len(dl.samples) - 1; last > 0 && now.Sub(dl.samples[last].at) < sampleInterval {
+		// update the latest sample instead of adding one for every write
+		dl.samples[last] = progressSample{at: now, written: dl.totalWrittenBytes}
+	} else {
+		dl.samples = append(dl.samples, progressSample{at: now, written: dl.totalWrittenBytes})
+	}
+
+	// keep the last sample before the window as a starting point
+	i := 0
+	for i < len(dl.samples)-2 && now.Sub(dl.samples[i+1].at) >= speedWindow {
+		i++
+	}
+	dl.samples = dl.samples[i:]
+}
+
+// speed returns the bytes per second within the window
+func (dl *progress) speed() float64 {
+	if len(dl.samples) < 2 {
+		return 0
+	}
+
+	first, last := dl.samples[0], dl.samples[len(dl.samples)-1]
+	elapsed := last.at.Sub(first.at)
+	if elapsed < sampleInterval {
+		// too short to be meaningful
+		return 0
+	}
+
+	return float64(last.written-first.written) / elapsed.Seconds()
+}
+
+// throttled checks whether the speed stayed below throttledSpeed over the whole window
+func (dl *progress) throttled() bool {
+	if len(dl.samples) < 2 || dl.rateLimit > 0 && dl.rateLimit < throttledSpeed {
+		return false
+	}
+
+	first, last := dl.samples[0], dl.samples[len(dl.samples)-1]
+	return last.at.Sub(first.at) >= speedWindow && dl.speed() < throttledSpeed
+}
+
+func (dl *progress) current() Progress {
+	speed := dl.speed()
+
+	if dl.contentLength <= 0 {
+		return Progress{
+			Downloaded: dl.totalWrittenBytes,
+			Total:      -1,
+			Percent:    -1,
+			Speed:      speed,
+			ETA:        -1,
+			Throttled:  dl.throttled(),
+		}
+	}
+
+	eta := time.Duration(-1)
+	if speed > 0 {
+		eta = time.Duration(float64(dl.contentLength-dl.totalWrittenBytes) / speed * float64(time.Second))
+	}
+
+	return Progress{
+		Downloaded: dl.totalWrittenBytes,
+		Total:      dl.contentLength,
+		Percent:    float64(dl.totalWrittenBytes) / float64(dl.contentLength) * 100,
+		Speed:      speed,
+		ETA:        eta,
+		Throttled:  dl.throttled(),
+	}
+}
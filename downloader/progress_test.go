@@ -0,0 +1,108 @@
+package downloader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress(t *testing.T) {
+	updates := make(chan Progress, 2)
+	prog := &progress{contentLength: 200, updates: updates}
+
+	prog.Write(make([]byte, 50))
+	prog.Write(make([]byte, 100))
+
+	assert.Equal(t, Progress{Downloaded: 50, Total: 200, Percent: 25, ETA: -1}, <-updates)
+	assert.Equal(t, Progress{Downloaded: 150, Total: 200, Percent: 75, ETA: -1}, <-updates)
+}
+
+func TestProgress_UnknownLength(t *testing.T) {
+	prog := &progress{}
+	prog.Write(make([]byte, 10))
+
+	assert.Equal(t, Progress{Downloaded: 10, Total: -1, Percent: -1, ETA: -1}, prog.current())
+}
+
+func TestProgress_Callback(t *testing.T) {
+	var got []Progress
+	prog := &progress{contentLength: 100, callback: func(p Progress) {
+		got = append(got, p)
+	}}
+
+	prog.Write(make([]byte, 40))
+	prog.Write(make([]byte, 60))
+
+	assert.Equal(t, []Progress{
+		{Downloaded: 40, Total: 100, Percent: 40, ETA: -1},
+		{Downloaded: 100, Total: 100, Percent: 100, ETA: -1},
+	}, got)
+}
+
+func TestProgress_SpeedAndETA(t *testing.T) {
+	prog := &progress{contentLength: 10000}
+	start := time.Now()
+
+	// 1000 bytes per second for 10 seconds, then 2000 bytes per second
+	for i := 0; i <= 10; i++ {
+		prog.totalWrittenBytes = int64(i) * 1000
+		prog.addSample(start.Add(time.Duration(i) * time.Second))
+	}
+	assert.InDelta(t, 1000, prog.speed(), 0.1)
+
+	for i := 1; i <= 5; i++ {
+		prog.totalWrittenBytes = 10000 + int64(i)*2000
+		prog.addSample(start.Add(time.Duration(10+i) * time.Second))
+	}
+	assert.InDelta(t, 2000, prog.speed(), 0.1)
+
+	prog.contentLength = 40000
+	assert.Equal(t, 10*time.Second, prog.current().ETA)
+}
+
+func TestProgress_Throttled(t *testing.T) {
+	prog := &progress{contentLength: 10 << 20}
+	start := time.Now()
+
+	// 1 MiB per second is fine
+	for i := 0; i <= 5; i++ {
+		prog.totalWrittenBytes = int64(i) << 20
+		prog.addSample(start.Add(time.Duration(i) * time.Second))
+	}
+	assert.False(t, prog.current().Throttled)
+
+	// 50 KiB per second over the whole window is throttled
+	for i := 1; i <= 6; i++ {
+		prog.totalWrittenBytes = 5<<20 + int64(i)*50<<10
+		prog.addSample(start.Add(time.Duration(5+i) * time.Second))
+	}
+	assert.True(t, prog.current().Throttled)
+}
+
+func TestProgress_ThrottledByRateLimit(t *testing.T) {
+	prog := &progress{contentLength: 10 << 20, rateLimit: 50 << 10}
+	start := time.Now()
+
+	// the speed is limited on purpose
+	for i := 0; i <= 6; i++ {
+		prog.totalWrittenBytes = int64(i) * 50 << 10
+		prog.addSample(start.Add(time.Duration(i) * time.Second))
+	}
+	assert.False(t, prog.current().Throttled)
+
+	// a limit above the threshold doesn't hide throttling
+	prog.rateLimit = 1 << 20
+	assert.True(t, prog.current().Throttled)
+}
+
+func TestProgress_DoesNotBlock(t *testing.T) {
+	// nobody receives the updates, e.g. after the consumer gave up
+	updates := make(chan Progress)
+	prog := &progress{contentLength: 100, updates: updates}
+
+	n, err := prog.Write(make([]byte, 10))
+	assert.NoError(t, err)
+	assert.Equal(t, 10, n)
+	assert.EqualValues(t, 10, prog.totalWrittenBytes)
+}
@@ -0,0 +1,56 @@
+package downloader
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// rateLimitedReader limits the throughput of a reader with a token bucket
+// which allows bursts of up to one second. Waiting stops when ctx is done.
+type rateLimitedReader struct {
+	ctx    context.Context
+	reader io.Reader
+	limit  int64 // bytes per second
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimitedReader(ctx context.Context, reader io.Reader, limit int64) *rateLimitedReader {
+	return &rateLimitedReader{
+		ctx:    ctx,
+		reader: reader,
+		limit:  limit,
+		tokens: float64(limit),
+		last:   time.Now(),
+	}
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > r.limit {
+		p = p[:r.limit]
+	}
+
+	r.refill()
+	if missing := float64(len(p)) - r.tokens; missing > 0 {
+		timer := time.NewTimer(time.Duration(missing / float64(r.limit) * float64(time.Second)))
+		select {
+		case <-r.ctx.Done():
+			timer.Stop()
+			return 0, r.ctx.Err()
+		case <-timer.C:
+		}
+		r.refill()
+	}
+
+	n, err := r.reader.Read(p)
+	r.tokens -= float64(n)
+
+	return n, err
+}
+
+func (r *rateLimitedReader) refill() {
+	now := time.Now()
+	r.tokens = min(r.tokens+now.Sub(r.last).Seconds()*float64(r.limit), float64(r.limit))
+	r.last = now
+}
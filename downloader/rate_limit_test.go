@@ -0,0 +1,38 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitedReader(t *testing.T) {
+	data := make([]byte, 3000)
+	reader := newRateLimitedReader(context.Background(), bytes.NewReader(data), 2000)
+
+	start := time.Now()
+	written, err := io.Copy(io.Discard, reader)
+	require.NoError(t, err)
+
+	// the first 2000 bytes are a burst, the rest takes half a second
+	assert.EqualValues(t, len(data), written)
+	assert.GreaterOrEqual(t, time.Since(start), 450*time.Millisecond)
+}
+
+func TestRateLimitedReader_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	reader := newRateLimitedReader(ctx, bytes.NewReader(make([]byte, 3000)), 1000)
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	// the reader stops waiting for tokens instead of sleeping for two seconds
+	start := time.Now()
+	_, err := io.Copy(io.Discard, reader)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+}
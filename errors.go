@@ -2,18 +2,30 @@ package youtube
 
 import (
 	"fmt"
+	"strings"
 )
 
 const (
 	ErrCipherNotFound             = constError("cipher not found")
 	ErrSignatureTimestampNotFound = constError("signature timestamp not found")
 	ErrInvalidCharactersInVideoID = constError("invalid characters in video id")
-	ErrVideoIDMinLength           = constError("the video id must be at least 10 characters long")
+	ErrVideoIDMinLength           = constError("the video id must be at least 11 characters long")
+	ErrVideoIDMaxLength           = constError("the video id must be at most 11 characters long")
 	ErrReadOnClosedResBody        = constError("http: read on closed response body")
 	ErrNotPlayableInEmbed         = constError("embedding of this video has been disabled")
 	ErrLoginRequired              = constError("login required to confirm your age")
 	ErrVideoPrivate               = constError("user restricted access to this video")
 	ErrInvalidPlaylist            = constError("no playlist detected or invalid playlist ID")
+	ErrContentLengthUnknown       = constError("the content length of the format is unknown")
+	ErrPlaybackUnavailable        = constError("video is unplayable")
+	ErrVideoUnavailable           = constError("video is unavailable")
+	ErrContentCheckRequired       = constError("confirmation required to watch potentially inappropriate content")
+	ErrLiveStreamOffline          = constError("live stream is offline")
+	ErrGeoRestricted              = constError("video is not available in your country")
+	ErrVideoRemoved               = constError("video has been removed")
+	ErrNoFormats                  = constError("no formats found in the server's answer")
+	ErrInvalidStreamURL           = constError("deciphered stream URL is invalid")
+	ErrAvailabilityCheckFailed    = constError("unable to check the availability of the video")
 )
 
 type constError string
@@ -31,6 +43,32 @@ func (err ErrPlayabiltyStatus) Error() string {
 	return fmt.Sprintf("cannot playback and download, status: %s, reason: %s", err.Status, err.Reason)
 }
 
+// Unwrap maps well-known statuses to errors that can be checked with errors.Is
+func (err ErrPlayabiltyStatus) Unwrap() error {
+	switch err.Status {
+	case "UNPLAYABLE":
+		if strings.Contains(strings.ToLower(err.Reason), "country") {
+			return ErrGeoRestricted
+		}
+		return ErrPlaybackUnavailable
+	case "ERROR":
+		reason := strings.ToLower(err.Reason)
+		switch {
+		case strings.Contains(reason, "removed") || strings.Contains(reason, "terminated"):
+			return ErrVideoRemoved
+		case strings.Contains(reason, "private"):
+			return ErrVideoPrivate
+		}
+		return ErrVideoUnavailable
+	case "CONTENT_CHECK_REQUIRED":
+		return ErrContentCheckRequired
+	case "LIVE_STREAM_OFFLINE":
+		return ErrLiveStreamOffline
+	}
+
+	return nil
+}
+
 // ErrUnexpectedStatusCode is returned on unexpected HTTP status codes
 type ErrUnexpectedStatusCode int
 
@@ -1,6 +1,7 @@
 package youtube
 
 import (
+	"errors"
 	"strconv"
 	"testing"
 
@@ -24,3 +25,66 @@ func TestErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestErrPlayabiltyStatus_Unwrap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		status   string
+		expected error
+	}{
+		{"UNPLAYABLE", ErrPlaybackUnavailable},
+		{"ERROR", ErrVideoUnavailable},
+		{"CONTENT_CHECK_REQUIRED", ErrContentCheckRequired},
+		{"LIVE_STREAM_OFFLINE", ErrLiveStreamOffline},
+		{"unknown", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			var err error = &ErrPlayabiltyStatus{Status: tt.status, Reason: "for that reason"}
+
+			assert.Equal(t, tt.expected, errors.Unwrap(err))
+			if tt.expected != nil {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+
+			var status *ErrPlayabiltyStatus
+			if assert.ErrorAs(t, err, &status) {
+				assert.Equal(t, "for that reason", status.Reason)
+			}
+		})
+	}
+}
+
+func TestErrPlayabiltyStatus_GeoRestricted(t *testing.T) {
+	t.Parallel()
+
+	err := &ErrPlayabiltyStatus{Status: "UNPLAYABLE", Reason: "The uploader has not made this video available in your country"}
+
+	assert.ErrorIs(t, err, ErrGeoRestricted)
+	assert.NotErrorIs(t, err, ErrPlaybackUnavailable)
+}
+
+func TestErrPlayabiltyStatus_Reasons(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		reason   string
+		expected error
+	}{
+		{"Video unavailable", ErrVideoUnavailable},
+		{"This video has been removed by the uploader", ErrVideoRemoved},
+		{"This video is no longer available because the YouTube account associated with this video has been terminated.", ErrVideoRemoved},
+		{"This video is private", ErrVideoPrivate},
+		// reasons in other languages aren't known, player requests are sent in English
+		{"Dieses Video wurde vom Uploader entfernt", ErrVideoUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			err := &ErrPlayabiltyStatus{Status: "ERROR", Reason: tt.reason}
+
+			assert.ErrorIs(t, err, tt.expected)
+			assert.Contains(t, err.Error(), tt.reason)
+		})
+	}
+}
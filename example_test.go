@@ -3,6 +3,9 @@ package youtube_test
 import (
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
 	"os"
 	"strings"
 
@@ -38,6 +41,40 @@ func ExampleClient() {
 	}
 }
 
+// Example usage for adaptive formats: picking the best video-only stream.
+func ExampleFormatList_Kind() {
+	client := youtube.Client{}
+
+	video, err := client.GetVideo("BaW_jenozKc")
+	if err != nil {
+		panic(err)
+	}
+
+	// Adaptive streams go beyond 720p but carry either video or audio.
+	// Use the downloader package to merge them with an audio stream.
+	formats := video.Formats.Kind(youtube.FormatVideoOnly)
+	formats.Sort()
+
+	fmt.Printf("Best video stream: itag %d (%s)\n", formats[0].ItagNo, formats[0].QualityLabel)
+}
+
+// Example usage for authenticated requests: passing the cookies of a signed-in session.
+func ExampleClient_cookies() {
+	jar, _ := cookiejar.New(nil)
+	jar.SetCookies(&url.URL{Scheme: "https", Host: "www.youtube.com"}, []*http.Cookie{
+		{Name: "SID", Value: "exported from the browser", Domain: ".youtube.com", Path: "/"},
+	})
+
+	client := youtube.Client{HTTPClient: &http.Client{Jar: jar}}
+
+	video, err := client.GetVideo("BaW_jenozKc")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(video.Title)
+}
+
 // Example usage for playlists: downloading and checking information.
 func ExamplePlaylist() {
 	playlistID := "PLQZgI7en5XEgM0L1_ZcKmEzxW1sCOVZwP"
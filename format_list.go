@@ -1,6 +1,8 @@
 package youtube
 
 import (
+	"cmp"
+	"mime"
 	"sort"
 	"strconv"
 	"strings"
@@ -32,6 +34,13 @@ func (list FormatList) Type(value string) FormatList {
 	})
 }
 
+// Kind returns a new FormatList filtered by the kind of stream
+func (list FormatList) Kind(kind FormatKind) FormatList {
+	return list.Select(func(f Format) bool {
+		return f.Kind() == kind
+	})
+}
+
 // Type returns a new FormatList filtered by display name
 func (list FormatList) Language(displayName string) FormatList {
 	return list.Select(func(f Format) bool {
@@ -63,22 +72,195 @@ func (list FormatList) WithAudioChannels() FormatList {
 	})
 }
 
+// MinFPS returns a new FormatList filtered by video formats with at least the given frame rate, e.g. 48 for high frame rates
+func (list FormatList) MinFPS(fps int) FormatList {
+	return list.Select(func(f Format) bool {
+		return f.FPS >= fps
+	})
+}
+
+// HDR returns a new FormatList filtered by whether formats are HDR
+func (list FormatList) HDR(hdr bool) FormatList {
+	return list.Select(func(f Format) bool {
+		return f.IsHDR() == hdr
+	})
+}
+
+// Spherical returns a new FormatList filtered by whether formats are 360° videos
+func (list FormatList) Spherical(spherical bool) FormatList {
+	return list.Select(func(f Format) bool {
+		return f.Is360() == spherical
+	})
+}
+
+// MaxHeight returns a new FormatList filtered by video formats not higher than the given height
+func (list FormatList) MaxHeight(height int) FormatList {
+	return list.Select(func(f Format) bool {
+		return f.Height > 0 && f.Height <= height
+	})
+}
+
+// QualityLabels returns the distinct quality labels, e.g. 1080p60, ordered from highest to lowest resolution
+func (list FormatList) QualityLabels() []string {
+	videos := list.Select(func(f Format) bool {
+		return f.QualityLabel != ""
+	})
+
+	sort.SliceStable(videos, func(i, j int) bool {
+		if videos[i].Height == videos[j].Height {
+			return videos[i].FPS > videos[j].FPS
+		}
+		return videos[i].Height > videos[j].Height
+	})
+
+	var labels []string
+	seen := map[string]bool{}
+	for _, f := range videos {
+		if !seen[f.QualityLabel] {
+			seen[f.QualityLabel] = true
+			labels = append(labels, f.QualityLabel)
+		}
+	}
+
+	return labels
+}
+
+// Best returns the format with the highest resolution, then the highest bitrate
+func (list FormatList) Best() (Format, error) {
+	if len(list) == 0 {
+		return Format{}, ErrNoFormat
+	}
+
+	best := list[0]
+	for _, f := range list[1:] {
+		if compareFormatSize(f, best) > 0 {
+			best = f
+		}
+	}
+
+	return best, nil
+}
+
+// Worst returns the format with the lowest resolution, then the lowest bitrate, e.g. for previews.
+// Formats without video are skipped, unless the list has no other formats, e.g. for the worst audio format.
+func (list FormatList) Worst() (Format, error) {
+	if len(list) == 0 {
+		return Format{}, ErrNoFormat
+	}
+
+	videos := list.Select(func(f Format) bool {
+		return f.Width*f.Height > 0
+	})
+	if len(videos) == 0 {
+		videos = list
+	}
+
+	worst := videos[0]
+	for _, f := range videos[1:] {
+		if compareFormatSize(f, worst) < 0 {
+			worst = f
+		}
+	}
+
+	return worst, nil
+}
+
+// compareFormatSize compares the resolution and then the bitrate of two formats
+func compareFormatSize(a, b Format) int {
+	if c := cmp.Compare(a.Width*a.Height, b.Width*b.Height); c != 0 {
+		return c
+	}
+
+	return cmp.Compare(a.Bitrate, b.Bitrate)
+}
+
 // FilterQuality reduces the format list to formats matching the quality
 func (v *Video) FilterQuality(quality string) {
 	v.Formats = v.Formats.Quality(quality)
 	v.Formats.Sort()
 }
 
-// Sort sorts all formats fields
+// defaultVideoCodecs is the order of video codecs used by Sort, preferring smaller files
+var defaultVideoCodecs = []string{"av1", "vp9", "h264"}
+
+// Sort sorts all formats fields.
+// Video codecs which aren't av1, vp9 (including vp09) or h264 come first, as they always did.
 func (list FormatList) Sort() {
+	list.sortByCodecs(defaultVideoCodecs, -1)
+}
+
+// SortByCodecs sorts like Sort, but prefers video codecs in the given order when formats
+// have the same resolution and FPS, e.g. "h264", "vp9", "av1" for compatibility.
+// Codecs missing from the order come last. Without codecs, it sorts like Sort.
+func (list FormatList) SortByCodecs(codecs ...string) {
+	if len(codecs) == 0 {
+		list.Sort()
+		return
+	}
+
+	list.sortByCodecs(codecs, len(codecs))
+}
+
+// sortByCodecs sorts the list with the given rank for codecs missing from codecs
+func (list FormatList) sortByCodecs(codecs []string, unknownRank int) {
 	sort.SliceStable(list, func(i, j int) bool {
-		return sortFormat(i, j, list)
+		return sortFormat(i, j, list, codecs, unknownRank)
 	})
 }
 
-// sortFormat sorts video by resolution, FPS, codec (av01, vp9, avc1), bitrate
+// PreferContainer moves formats of the given container, e.g. "mp4", before the other formats
+// with the same resolution and FPS. The list should be sorted before.
+func (list FormatList) PreferContainer(container string) {
+	for start := 0; start < len(list); {
+		end := start + 1
+		for end < len(list) && list[end].Width == list[start].Width && list[end].FPS == list[start].FPS {
+			end++
+		}
+
+		group := list[start:end]
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].Container() == container && group[j].Container() != container
+		})
+
+		start = end
+	}
+}
+
+// videoCodecName returns the name of the video codec of a mime type, e.g. "h264" for avc1.640028
+func videoCodecName(mimeType string) string {
+	_, params, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return ""
+	}
+
+	codec, _, _ := strings.Cut(params["codecs"], ".")
+	switch codec {
+	case "av01":
+		return "av1"
+	case "vp9", "vp09":
+		return "vp9"
+	case "avc1":
+		return "h264"
+	}
+
+	return codec
+}
+
+// videoCodecRank returns the position of the video codec within codecs, or unknownRank if it is missing
+func videoCodecRank(mimeType string, codecs []string, unknownRank int) int {
+	name := videoCodecName(mimeType)
+	for i, codec := range codecs {
+		if codec == name {
+			return i
+		}
+	}
+
+	return unknownRank
+}
+
+// sortFormat sorts video by resolution, FPS, codec (av01, vp9, avc1 by default), bitrate
 // sorts audio by default, codec (mp4, opus), channels, bitrate, sample rate
-func sortFormat(i int, j int, formats FormatList) bool {
+func sortFormat(i int, j int, formats FormatList, videoCodecs []string, unknownRank int) bool {
 
 	// Sort by Width
 	if formats[i].Width == formats[j].Width {
@@ -126,21 +308,13 @@ func sortFormat(i int, j int, formats FormatList) bool {
 			}
 			// Video
 			// Sort by codec
-			codec := map[int]int{}
-			for _, index := range []int{i, j} {
-				if strings.Contains(formats[index].MimeType, "av01") {
-					codec[index] = 1
-				} else if strings.Contains(formats[index].MimeType, "vp9") {
-					codec[index] = 2
-				} else if strings.Contains(formats[index].MimeType, "avc1") {
-					codec[index] = 3
-				}
-			}
-			if codec[i] == codec[j] {
+			codecI := videoCodecRank(formats[i].MimeType, videoCodecs, unknownRank)
+			codecJ := videoCodecRank(formats[j].MimeType, videoCodecs, unknownRank)
+			if codecI == codecJ {
 				// Sort by Audio Bitrate
 				return formats[i].Bitrate > formats[j].Bitrate
 			}
-			return codec[i] < codec[j]
+			return codecI < codecJ
 		}
 		return formats[i].FPS > formats[j].FPS
 	}
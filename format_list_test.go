@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type filter struct {
@@ -144,3 +145,151 @@ func TestFormatList_Sort(t *testing.T) {
 		{Width: 512},
 	}, list)
 }
+
+func TestFormatList_Kind(t *testing.T) {
+	t.Parallel()
+
+	progressive := Format{ItagNo: 18, MimeType: `video/mp4; codecs="avc1.42001E, mp4a.40.2"`, AudioChannels: 2}
+	videoOnly := Format{ItagNo: 137, MimeType: `video/mp4; codecs="avc1.640028"`}
+	audioOnly := Format{ItagNo: 251, MimeType: `audio/webm; codecs="opus"`, AudioChannels: 2}
+
+	list := FormatList{progressive, videoOnly, audioOnly}
+
+	assert.Equal(t, FormatList{progressive}, list.Kind(FormatProgressive))
+	assert.Equal(t, FormatList{videoOnly}, list.Kind(FormatVideoOnly))
+	assert.Equal(t, FormatList{audioOnly}, list.Kind(FormatAudioOnly))
+
+	// muxed formats without audioChannels are still progressive
+	muxed := Format{ItagNo: 18, MimeType: `video/mp4; codecs="avc1.42001E, mp4a.40.2"`}
+	assert.Equal(t, FormatProgressive, muxed.Kind())
+
+	// without codecs the audio channels decide
+	assert.Equal(t, FormatProgressive, (&Format{MimeType: "video/mp4", AudioChannels: 2}).Kind())
+	assert.Equal(t, FormatVideoOnly, (&Format{MimeType: "video/mp4"}).Kind())
+}
+
+func TestFormatList_QualityLabels(t *testing.T) {
+	t.Parallel()
+
+	list := FormatList{
+		{QualityLabel: "240p", Height: 240},
+		{QualityLabel: "1080p", Height: 1080, FPS: 30},
+		{QualityLabel: "720p", Height: 720},
+		{QualityLabel: "1080p60", Height: 1080, FPS: 60},
+		{MimeType: "audio/mp4"},
+		{QualityLabel: "240p", Height: 240},
+	}
+
+	assert.Equal(t, []string{"1080p60", "1080p", "720p", "240p"}, list.QualityLabels())
+}
+
+func TestFormatList_MaxHeight(t *testing.T) {
+	t.Parallel()
+
+	list := FormatList{
+		{ItagNo: 137, Height: 1080},
+		{ItagNo: 22, Height: 720},
+		{ItagNo: 18, Height: 360},
+		{ItagNo: 140},
+	}
+
+	assert.Equal(t, FormatList{{ItagNo: 22, Height: 720}, {ItagNo: 18, Height: 360}}, list.MaxHeight(720))
+	assert.Empty(t, list.MaxHeight(144))
+}
+
+func TestFormatList_SortByCodecs(t *testing.T) {
+	t.Parallel()
+
+	av1 := Format{ItagNo: 399, Width: 1920, MimeType: `video/mp4; codecs="av01.0.08M.08"`}
+	vp9 := Format{ItagNo: 248, Width: 1920, MimeType: `video/webm; codecs="vp09.00.40.08"`}
+	h264 := Format{ItagNo: 299, Width: 1920, MimeType: `video/mp4; codecs="avc1.640028"`}
+	small := Format{ItagNo: 136, Width: 1280, MimeType: `video/mp4; codecs="avc1.4d401f"`}
+
+	list := FormatList{small, h264, vp9, av1}
+	list.SortByCodecs()
+	assert.Equal(t, FormatList{av1, vp9, h264, small}, list)
+
+	// codecs missing in the preference come last
+	list.SortByCodecs("h264", "vp9")
+	assert.Equal(t, FormatList{h264, vp9, av1, small}, list)
+}
+
+func TestFormatList_SortUnknownCodecs(t *testing.T) {
+	t.Parallel()
+
+	h264 := Format{ItagNo: 18, Width: 640, MimeType: `video/mp4; codecs="avc1.42001E, mp4a.40.2"`}
+	mp4v := Format{ItagNo: 0, Width: 640, MimeType: `video/3gpp; codecs="mp4v.20.3, mp4a.40.2"`}
+	vp9 := Format{ItagNo: 243, Width: 640, MimeType: `video/webm; codecs="vp9"`}
+
+	// Sort keeps unknown codecs first
+	list := FormatList{h264, vp9, mp4v}
+	list.Sort()
+	assert.Equal(t, FormatList{mp4v, vp9, h264}, list)
+
+	// a preference puts them last
+	list.SortByCodecs("h264", "vp9")
+	assert.Equal(t, FormatList{h264, vp9, mp4v}, list)
+}
+
+func TestFormatList_BestAndWorst(t *testing.T) {
+	t.Parallel()
+
+	list := FormatList{
+		{ItagNo: 18, Width: 640, Height: 360, Bitrate: 500},
+		{ItagNo: 22, Width: 1280, Height: 720, Bitrate: 1000},
+		{ItagNo: 136, Width: 1280, Height: 720, Bitrate: 2000},
+		{ItagNo: 140, Bitrate: 128},
+	}
+
+	best, err := list.Best()
+	require.NoError(t, err)
+	assert.Equal(t, 136, best.ItagNo)
+
+	// audio formats have no resolution, but aren't the worst video
+	worst, err := list.Worst()
+	require.NoError(t, err)
+	assert.Equal(t, 18, worst.ItagNo)
+
+	audio := FormatList{{ItagNo: 251, Bitrate: 160}, {ItagNo: 140, Bitrate: 128}}
+	worst, err = audio.Worst()
+	require.NoError(t, err)
+	assert.Equal(t, 140, worst.ItagNo)
+
+	_, err = FormatList{}.Best()
+	assert.ErrorIs(t, err, ErrNoFormat)
+	_, err = FormatList{}.Worst()
+	assert.ErrorIs(t, err, ErrNoFormat)
+}
+
+func TestFormatList_PreferContainer(t *testing.T) {
+	t.Parallel()
+
+	av1 := Format{ItagNo: 399, Width: 1920, MimeType: `video/mp4; codecs="av01.0.08M.08"`}
+	vp9 := Format{ItagNo: 248, Width: 1920, MimeType: `video/webm; codecs="vp9"`}
+	small := Format{ItagNo: 136, Width: 1280, MimeType: `video/mp4; codecs="avc1.4d401f"`}
+	smallWebm := Format{ItagNo: 247, Width: 1280, MimeType: `video/webm; codecs="vp9"`}
+
+	list := FormatList{av1, vp9, small, smallWebm}
+	list.PreferContainer("webm")
+	assert.Equal(t, FormatList{vp9, av1, smallWebm, small}, list)
+
+	// the resolution takes precedence over the container
+	list = FormatList{av1, smallWebm}
+	list.PreferContainer("webm")
+	assert.Equal(t, FormatList{av1, smallWebm}, list)
+
+	assert.Equal(t, "webm", vp9.Container())
+	assert.Equal(t, "mp4", (&Format{MimeType: `audio/mp4; codecs="mp4a.40.2"`}).Container())
+}
+
+func TestFormatList_MinFPS(t *testing.T) {
+	t.Parallel()
+
+	list := FormatList{
+		{ItagNo: 299, QualityLabel: "1080p60", FPS: 60},
+		{ItagNo: 137, QualityLabel: "1080p", FPS: 30},
+		{ItagNo: 140},
+	}
+
+	assert.Equal(t, FormatList{{ItagNo: 299, QualityLabel: "1080p60", FPS: 60}}, list.MinFPS(48))
+}
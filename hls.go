@@ -0,0 +1,209 @@
+package youtube
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrNoHLSManifest = errors.New("no HLS manifest provided")
+
+var hlsBandwidthPattern = regexp.MustCompile(`(?:^|,)BANDWIDTH=(\d+)`)
+
+// hlsPlaylist is a parsed HLS master or media playlist
+type hlsPlaylist struct {
+	variants       []hlsVariant // only set for master playlists
+	segments       []hlsSegment // only set for media playlists
+	mediaSequence  int64
+	targetDuration time.Duration
+	ended          bool
+}
+
+type hlsVariant struct {
+	bandwidth int
+	uri       string
+}
+
+type hlsSegment struct {
+	duration time.Duration
+	uri      string
+}
+
+// GetHLSStream returns a stream of the live video which records the given duration.
+// A duration of 0 records until the stream ends.
+func (c *Client) GetHLSStream(video *Video, duration time.Duration) (io.ReadCloser, error) {
+	return c.GetHLSStreamContext(context.Background(), video, duration)
+}
+
+// GetHLSStreamContext returns a stream of the live video which records the given duration.
+// The segments of the variant with the highest bandwidth are concatenated as MPEG-TS.
+// Recording starts at the first segment of the playlist, which is in the past for streams with IsLiveDVR.
+func (c *Client) GetHLSStreamContext(ctx context.Context, video *Video, duration time.Duration) (io.ReadCloser, error) {
+	if video.HLSManifestURL == "" {
+		return nil, ErrNoHLSManifest
+	}
+
+	c = c.forVideo(video)
+	c.assureClient()
+
+	playlistURL := video.HLSManifestURL
+	master, err := c.getHLSPlaylist(ctx, playlistURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(master.variants) > 0 {
+		best := master.variants[0]
+		for _, variant := range master.variants[1:] {
+			if variant.bandwidth > best.bandwidth {
+				best = variant
+			}
+		}
+		playlistURL = best.uri
+	}
+
+	r, w := io.Pipe()
+	go c.recordHLS(ctx, playlistURL, duration, w)
+
+	return r, nil
+}
+
+// recordHLS polls the media playlist and writes new segments until the duration is recorded
+func (c *Client) recordHLS(ctx context.Context, playlistURL string, duration time.Duration, w *io.PipeWriter) {
+	var recorded time.Duration
+	next := int64(-1)
+
+	for {
+		playlist, err := c.getHLSPlaylist(ctx, playlistURL)
+		if err != nil {
+			w.CloseWithError(err)
+			return
+		}
+
+		for i, segment := range playlist.segments {
+			sequence := playlist.mediaSequence + int64(i)
+			if sequence < next {
+				continue
+			}
+
+			if duration > 0 && recorded >= duration {
+				w.Close()
+				return
+			}
+
+			if err := c.copyHLSSegment(ctx, w, segment.uri); err != nil {
+				w.CloseWithError(err)
+				return
+			}
+
+			recorded += segment.duration
+			next = sequence + 1
+		}
+
+		if playlist.ended || (duration > 0 && recorded >= duration) {
+			w.Close()
+			return
+		}
+
+		// wait for the next segments to be published
+		select {
+		case <-ctx.Done():
+			w.CloseWithError(ctx.Err())
+			return
+		case <-time.After(max(playlist.targetDuration/2, time.Second)):
+		}
+	}
+}
+
+func (c *Client) copyHLSSegment(ctx context.Context, w io.Writer, segmentURL string) error {
+	resp, err := c.httpGet(ctx, segmentURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+func (c *Client) getHLSPlaylist(ctx context.Context, playlistURL string) (*hlsPlaylist, error) {
+	base, err := url.Parse(playlistURL)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.httpGetBodyBytes(ctx, playlistURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseHLSPlaylist(body, base)
+}
+
+// parseHLSPlaylist parses the tags of a master or media playlist which are needed for recording
+func parseHLSPlaylist(data []byte, base *url.URL) (*hlsPlaylist, error) {
+	if !bytes.HasPrefix(data, []byte("#EXTM3U")) {
+		return nil, errors.New("invalid HLS playlist")
+	}
+
+	playlist := &hlsPlaylist{}
+
+	var (
+		variant  *hlsVariant
+		segment  *hlsSegment
+		scanner  = bufio.NewScanner(bytes.NewReader(data))
+		parseInt = func(s string) int64 {
+			i, _ := strconv.ParseInt(s, 10, 64)
+			return i
+		}
+	)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXT-X-STREAM-INF:"):
+			variant = &hlsVariant{}
+			if match := hlsBandwidthPattern.FindStringSubmatch(line[len("#EXT-X-STREAM-INF:"):]); match != nil {
+				variant.bandwidth = int(parseInt(match[1]))
+			}
+		case strings.HasPrefix(line, "#EXTINF:"):
+			seconds, _, _ := strings.Cut(line[len("#EXTINF:"):], ",")
+			value, _ := strconv.ParseFloat(seconds, 64)
+			segment = &hlsSegment{duration: time.Duration(value * float64(time.Second))}
+		case strings.HasPrefix(line, "#EXT-X-MEDIA-SEQUENCE:"):
+			playlist.mediaSequence = parseInt(line[len("#EXT-X-MEDIA-SEQUENCE:"):])
+		case strings.HasPrefix(line, "#EXT-X-TARGETDURATION:"):
+			playlist.targetDuration = time.Duration(parseInt(line[len("#EXT-X-TARGETDURATION:"):])) * time.Second
+		case line == "#EXT-X-ENDLIST":
+			playlist.ended = true
+		case strings.HasPrefix(line, "#"):
+			// ignore other tags and comments
+		default:
+			uri, err := base.Parse(line)
+			if err != nil {
+				return nil, err
+			}
+
+			if variant != nil {
+				variant.uri = uri.String()
+				playlist.variants = append(playlist.variants, *variant)
+				variant = nil
+			} else if segment != nil {
+				segment.uri = uri.String()
+				playlist.segments = append(playlist.segments, *segment)
+				segment = nil
+			}
+		}
+	}
+
+	return playlist, scanner.Err()
+}
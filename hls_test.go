@@ -0,0 +1,92 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHLSPlaylist(t *testing.T) {
+	base, _ := url.Parse("https://example.com/live/master.m3u8")
+
+	master, err := parseHLSPlaylist([]byte(`#EXTM3U
+#EXT-X-STREAM-INF:BANDWIDTH=1280000,AVERAGE-BANDWIDTH=9000000,CODECS="avc1.4d401f,mp4a.40.2"
+low/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=2560000,CODECS="avc1.4d401f,mp4a.40.2"
+https://cdn.example.com/high/index.m3u8
+`), base)
+	require.NoError(t, err)
+	assert.Equal(t, []hlsVariant{
+		{bandwidth: 1280000, uri: "https://example.com/live/low/index.m3u8"},
+		{bandwidth: 2560000, uri: "https://cdn.example.com/high/index.m3u8"},
+	}, master.variants)
+
+	media, err := parseHLSPlaylist([]byte(`#EXTM3U
+#EXT-X-TARGETDURATION:5
+#EXT-X-MEDIA-SEQUENCE:42
+#EXTINF:5.0,
+seg42.ts
+#EXTINF:2.5,
+seg43.ts
+#EXT-X-ENDLIST
+`), base)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), media.mediaSequence)
+	assert.Equal(t, 5*time.Second, media.targetDuration)
+	assert.True(t, media.ended)
+	assert.Equal(t, []hlsSegment{
+		{duration: 5 * time.Second, uri: "https://example.com/live/seg42.ts"},
+		{duration: 2500 * time.Millisecond, uri: "https://example.com/live/seg43.ts"},
+	}, media.segments)
+
+	_, err = parseHLSPlaylist([]byte("<html>"), base)
+	assert.Error(t, err)
+}
+
+func TestClient_GetHLSStream(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/master.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=100\nlow.m3u8\n#EXT-X-STREAM-INF:BANDWIDTH=200\nhigh.m3u8\n")
+	})
+	mux.HandleFunc("/high.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "#EXTM3U\n#EXT-X-MEDIA-SEQUENCE:1\n#EXTINF:2,\na.ts\n#EXTINF:2,\nb.ts\n#EXTINF:2,\nc.ts\n#EXT-X-ENDLIST\n")
+	})
+	mux.HandleFunc("/a.ts", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "a") })
+	mux.HandleFunc("/b.ts", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "b") })
+	mux.HandleFunc("/c.ts", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "c") })
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := Client{}
+	video := &Video{HLSManifestURL: server.URL + "/master.m3u8"}
+
+	tests := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{0, "abc"},
+		{3 * time.Second, "ab"},
+	}
+	for _, tt := range tests {
+		stream, err := client.GetHLSStreamContext(context.Background(), video, tt.duration)
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(stream)
+		require.NoError(t, err)
+		stream.Close()
+
+		assert.Equal(t, tt.expected, string(data))
+	}
+
+	_, err := client.GetHLSStream(&Video{}, 0)
+	assert.ErrorIs(t, err, ErrNoHLSManifest)
+}
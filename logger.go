@@ -6,23 +6,41 @@ import (
 	"os"
 )
 
-// The global logger for all Client instances
-var Logger = getLogger(os.Getenv("LOGLEVEL"))
+// The global logger for all Client instances without their own Logger
+var Logger = getDefaultLogger()
 
-func SetLogLevel(value string) {
-	Logger = getLogger(value)
+// SetLogLevel replaces the global logger with one of the given level (error/warn/info/debug)
+func SetLogLevel(value string) error {
+	logger, err := getLogger(value)
+	if err != nil {
+		return err
+	}
+
+	Logger = logger
+	return nil
+}
+
+// getDefaultLogger uses the LOGLEVEL environment variable, falling back to info on invalid values
+func getDefaultLogger() *slog.Logger {
+	logger, err := getLogger(os.Getenv("LOGLEVEL"))
+	if err != nil {
+		logger, _ = getLogger("")
+		logger.Warn("Ignoring LOGLEVEL", "error", err)
+	}
+
+	return logger
 }
 
-func getLogger(logLevel string) *slog.Logger {
+func getLogger(logLevel string) (*slog.Logger, error) {
 	levelVar := slog.LevelVar{}
 
 	if logLevel != "" {
 		if err := levelVar.UnmarshalText([]byte(logLevel)); err != nil {
-			panic(fmt.Sprintf("Invalid log level %s: %v", logLevel, err))
+			return nil, fmt.Errorf("invalid log level %s: %w", logLevel, err)
 		}
 	}
 
 	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 		Level: levelVar.Level(),
-	}))
+	})), nil
 }
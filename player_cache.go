@@ -1,37 +1,133 @@
 package youtube
 
 import (
+	"context"
+	"sync"
 	"time"
 )
 
 const defaultCacheExpiration = time.Minute * time.Duration(5)
 
+// sharedPlayerCache caches the JavaScript code of the player across all clients,
+// so concurrent downloads fetch and parse base.js only once.
+// It is keyed by the URL of base.js, so clients with another BaseURL don't share it.
+var sharedPlayerCache playerCache
+
+// ClearPlayerCache removes all cached players, so the next decipher fetches base.js again,
+// e.g. if YouTube changed the player without changing its URL. Running fetches aren't affected.
+func ClearPlayerCache() {
+	sharedPlayerCache.Clear()
+}
+
 type playerCache struct {
-	key       string
+	mu      sync.RWMutex
+	entries map[string]playerCacheEntry
+	fetches map[string]*playerFetch // running fetches by key
+}
+
+type playerCacheEntry struct {
 	expiredAt time.Time
 	config    playerConfig
 }
 
+// playerFetch is a running fetch of a player, which concurrent callers wait for
+type playerFetch struct {
+	done   chan struct{}
+	config playerConfig
+	err    error
+}
+
 // Get : get cache  when it has same video id and not expired
-func (s playerCache) Get(key string) playerConfig {
+func (s *playerCache) Get(key string) playerConfig {
 	return s.GetCacheBefore(key, time.Now())
 }
 
 // GetCacheBefore : can pass time for testing
-func (s playerCache) GetCacheBefore(key string, time time.Time) playerConfig {
-	if key == s.key && s.expiredAt.After(time) {
-		return s.config
+func (s *playerCache) GetCacheBefore(key string, time time.Time) playerConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.get(key, time)
+}
+
+func (s *playerCache) get(key string, time time.Time) playerConfig {
+	if entry, ok := s.entries[key]; ok && entry.expiredAt.After(time) {
+		return entry.config
 	}
 	return nil
 }
 
+// GetOrFetch : get cache or fetch it once for all concurrent callers of the same key
+func (s *playerCache) GetOrFetch(ctx context.Context, key string, fetch func() (playerConfig, error)) (playerConfig, error) {
+	s.mu.Lock()
+	if config := s.get(key, time.Now()); config != nil {
+		s.mu.Unlock()
+		return config, nil
+	}
+
+	if running, ok := s.fetches[key]; ok {
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-running.done:
+			return running.config, running.err
+		}
+	}
+
+	running := &playerFetch{done: make(chan struct{})}
+	if s.fetches == nil {
+		s.fetches = map[string]*playerFetch{}
+	}
+	s.fetches[key] = running
+	s.mu.Unlock()
+
+	running.config, running.err = fetch()
+
+	s.mu.Lock()
+	if running.err == nil {
+		s.set(key, running.config, time.Now().Add(defaultCacheExpiration))
+	}
+	delete(s.fetches, key)
+	s.mu.Unlock()
+
+	close(running.done)
+	return running.config, running.err
+}
+
 // Set : set cache with default expiration
 func (s *playerCache) Set(key string, operations playerConfig) {
 	s.setWithExpiredTime(key, operations, time.Now().Add(defaultCacheExpiration))
 }
 
 func (s *playerCache) setWithExpiredTime(key string, config playerConfig, time time.Time) {
-	s.key = key
-	s.config = config
-	s.expiredAt = time
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(key, config, time)
+}
+
+func (s *playerCache) set(key string, config playerConfig, expiredAt time.Time) {
+	if s.entries == nil {
+		s.entries = map[string]playerCacheEntry{}
+	}
+
+	// drop the players which expired in the meantime, e.g. of previous versions
+	now := time.Now()
+	for k, entry := range s.entries {
+		if !entry.expiredAt.After(now) {
+			delete(s.entries, k)
+		}
+	}
+
+	s.entries[key] = playerCacheEntry{expiredAt: expiredAt, config: config}
+}
+
+// Clear : remove all cached players
+func (s *playerCache) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = nil
 }
@@ -1,6 +1,12 @@
 package youtube
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -62,3 +68,103 @@ func TestPlayerCache(t *testing.T) {
 		})
 	}
 }
+
+func TestPlayerCache_Concurrent(t *testing.T) {
+	s := playerCache{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		key := fmt.Sprintf("player-%d", i%2)
+
+		go func() {
+			defer wg.Done()
+			s.Set(key, []byte("playerdata"))
+		}()
+
+		go func() {
+			defer wg.Done()
+			if got := s.Get(key); got != nil && string(got) != "playerdata" {
+				t.Errorf("Get() = %s", got)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := s.Get("player-0"); got == nil && s.Get("player-1") == nil {
+		t.Error("expected one of the players to be cached")
+	}
+}
+
+func TestClearPlayerCache(t *testing.T) {
+	const key = "https://www.youtube.com/s/player/clear/player_ias.vflset/en_US/base.js"
+
+	sharedPlayerCache.Set(key, []byte("playerdata"))
+	if got := sharedPlayerCache.Get(key); got == nil {
+		t.Fatal("expected the player to be cached")
+	}
+
+	ClearPlayerCache()
+
+	if got := sharedPlayerCache.Get(key); got != nil {
+		t.Errorf("Get() = %s after ClearPlayerCache()", got)
+	}
+
+	// the cache is usable after clearing it
+	sharedPlayerCache.Set(key, []byte("playerdata"))
+	if got := sharedPlayerCache.Get(key); got == nil {
+		t.Error("expected the player to be cached again")
+	}
+	ClearPlayerCache()
+}
+
+func TestClient_decipherURLConcurrent(t *testing.T) {
+	const playerPath = "/s/player/concurrent/player_ias.vflset/en_US/base.js"
+
+	newServer := func(fetches *atomic.Int32) *httptest.Server {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/embed/", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `<script>{"jsUrl":"%s"}</script>`, playerPath)
+		})
+		mux.HandleFunc(playerPath, func(w http.ResponseWriter, r *http.Request) {
+			fetches.Add(1)
+			// give the other decoders time to miss the cache
+			time.Sleep(50 * time.Millisecond)
+			w.Write(testPlayerConfig)
+		})
+		return httptest.NewServer(mux)
+	}
+
+	// the same player path on two hosts, e.g. a mirror and YouTube
+	var fetchesA, fetchesB atomic.Int32
+	serverA := newServer(&fetchesA)
+	defer serverA.Close()
+	serverB := newServer(&fetchesB)
+	defer serverB.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		baseURL := serverA.URL
+		if i%2 == 1 {
+			baseURL = serverB.URL
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			client := Client{client: &WebClient, BaseURL: baseURL}
+			uri, err := client.decipherURL(context.Background(), "BaW_jenozKc", "s=abcdefgh&sp=sig&url=https%3A%2F%2Fexample.com%2Fvideoplayback%3Fn%3Dabc")
+			if err != nil {
+				t.Errorf("decipherURL() error = %v", err)
+			} else if uri != "https://example.com/videoplayback?n=cba_&sig=egfhdcb" {
+				t.Errorf("decipherURL() = %s", uri)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if fetchesA.Load() != 1 || fetchesB.Load() != 1 {
+		t.Errorf("base.js fetched %d and %d times, want once per host", fetchesA.Load(), fetchesB.Load())
+	}
+}
@@ -4,10 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -15,8 +15,11 @@ type playerConfig []byte
 
 var basejsPattern = regexp.MustCompile(`(/s/player/\w+/player_ias.vflset/\w+/base.js)`)
 
+// signatureTimestampPattern matches the timestamp of the player, e.g. signatureTimestamp:19716
+var signatureTimestampPattern = regexp.MustCompile(`(?:signatureTimestamp|sts):(\d+)`)
+
 func (c *Client) getPlayerConfig(ctx context.Context, videoID string) (playerConfig, error) {
-	embedURL := fmt.Sprintf("https://youtube.com/embed/%s?hl=en", videoID)
+	embedURL := fmt.Sprintf("%s/embed/%s?hl=en", c.baseURL(), videoID)
 	embedBody, err := c.httpGetBodyBytes(ctx, embedURL)
 	if err != nil {
 		return nil, err
@@ -35,25 +38,42 @@ func (c *Client) getPlayerConfig(ctx context.Context, videoID string) (playerCon
 		artifactName = "player-" + parts[3] + ".js"
 		linkName := filepath.Join(artifactsFolder, "video-"+videoID+".js")
 		if err := os.Symlink(artifactName, linkName); err != nil {
-			log.Printf("unable to create symlink %s: %v", linkName, err)
+			c.logger().Error("unable to create symlink", "path", linkName, "error", err)
 		}
 	}
 
-	config := c.playerCache.Get(playerPath)
-	if config != nil {
+	playerURL := c.baseURL() + playerPath
+	return sharedPlayerCache.GetOrFetch(ctx, playerURL, func() (playerConfig, error) {
+		config, err := c.httpGetBodyBytes(ctx, playerURL)
+		if err != nil {
+			return nil, err
+		}
+
+		// for debugging
+		if artifactName != "" {
+			c.writeArtifact(artifactName, config)
+		}
+
 		return config, nil
-	}
+	})
+}
 
-	config, err = c.httpGetBodyBytes(ctx, "https://youtube.com"+playerPath)
+// getSignatureTimestamp returns the timestamp of the player for the video, which player requests
+// of web clients have to include to get signatures matching the player.
+func (c *Client) getSignatureTimestamp(ctx context.Context, videoID string) (int, error) {
+	config, err := c.getPlayerConfig(ctx, videoID)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	// for debugging
-	if artifactName != "" {
-		writeArtifact(artifactName, config)
+	return config.getSignatureTimestamp()
+}
+
+func (config playerConfig) getSignatureTimestamp() (int, error) {
+	result := signatureTimestampPattern.FindSubmatch(config)
+	if result == nil {
+		return 0, ErrSignatureTimestampNotFound
 	}
 
-	c.playerCache.Set(playerPath, config)
-	return config, nil
+	return strconv.Atoi(string(result[1]))
 }
@@ -143,9 +143,9 @@ func (p *Playlist) parsePlaylistInfo(ctx context.Context, client *Client, body [
 	p.Videos = entries
 
 	for continuation != "" {
-		data := prepareInnertubePlaylistData(continuation, true, *client.client)
+		data := client.prepareInnertubePlaylistData(continuation, true)
 
-		body, err := client.httpPostBodyBytes(ctx, "https://www.youtube.com/youtubei/v1/browse?key="+client.client.key, data)
+		body, err := client.httpPostBodyBytes(ctx, client.baseURL()+"/youtubei/v1/browse?key="+client.client.key, data)
 		if err != nil {
 			return err
 		}
@@ -203,7 +203,12 @@ func extractPlaylistEntries(data []byte) ([]*PlaylistEntry, string, error) {
 			continue
 		}
 
-		entries = append(entries, v.PlaylistEntry())
+		entry, err := v.PlaylistEntry()
+		if err != nil {
+			return nil, "", err
+		}
+
+		entries = append(entries, entry)
 	}
 
 	return entries, continuation, nil
@@ -228,18 +233,23 @@ type videosJSONExtractor struct {
 	} `json:"continuationItemRenderer"`
 }
 
-func (vje videosJSONExtractor) PlaylistEntry() *PlaylistEntry {
-	ds, err := strconv.Atoi(vje.Renderer.Duration)
-	if err != nil {
-		panic("invalid video duration: " + vje.Renderer.Duration)
+func (vje videosJSONExtractor) PlaylistEntry() (*PlaylistEntry, error) {
+	// live streams and premieres have no duration
+	var ds int
+	if vje.Renderer.Duration != "" {
+		var err error
+		if ds, err = strconv.Atoi(vje.Renderer.Duration); err != nil {
+			return nil, fmt.Errorf("invalid video duration %q: %w", vje.Renderer.Duration, err)
+		}
 	}
+
 	return &PlaylistEntry{
 		ID:         vje.Renderer.ID,
 		Title:      vje.Renderer.Title.String(),
 		Author:     vje.Renderer.Author.String(),
 		Duration:   time.Second * time.Duration(ds),
 		Thumbnails: vje.Renderer.Thumbnail.Thumbnails,
-	}
+	}, nil
 }
 
 type withRuns struct {
@@ -2,6 +2,7 @@ package youtube
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -70,3 +71,25 @@ func TestYoutube_extractPlaylistID(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractPlaylistEntries(t *testing.T) {
+	data := []byte(`[
+		{"playlistVideoRenderer": {"videoId": "9UL390els7M", "title": {"runs": [{"text": "first"}]}, "lengthSeconds": "61"}},
+		{"playlistVideoRenderer": {"videoId": "dsUXAEzaC3Q", "title": {"runs": [{"text": "live"}]}}},
+		{"continuationItemRenderer": {"continuationEndpoint": {"continuationCommand": {"token": "next"}}}}
+	]`)
+
+	entries, continuation, err := extractPlaylistEntries(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "next", continuation)
+
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "first", entries[0].Title)
+		assert.Equal(t, 61*time.Second, entries[0].Duration)
+		assert.Equal(t, "live", entries[1].Title)
+		assert.Zero(t, entries[1].Duration)
+	}
+
+	_, _, err = extractPlaylistEntries([]byte(`[{"playlistVideoRenderer": {"videoId": "9UL390els7M", "lengthSeconds": "1:01"}}]`))
+	assert.ErrorContains(t, err, "invalid video duration")
+}
@@ -0,0 +1,72 @@
+package youtube
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// proxyTransports keeps a transport per base transport and proxy URL, so connections are reused
+var proxyTransports sync.Map
+
+type proxyKey struct {
+	base  *http.Transport
+	proxy string
+}
+
+// withProxy returns a copy of the client sending its requests through the next proxy of ProxyProvider,
+// or the client itself if there is no ProxyProvider or it returned no proxy
+func (c *Client) withProxy() (*Client, error) {
+	if c.ProxyProvider == nil {
+		return c, nil
+	}
+
+	proxy := c.ProxyProvider()
+	if proxy == "" {
+		return c, nil
+	}
+
+	httpClient, err := c.proxyHTTPClient(proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	client := *c
+	client.HTTPClient = httpClient
+	return &client, nil
+}
+
+// proxyHTTPClient returns a copy of HTTPClient with a transport using the given proxy
+func (c *Client) proxyHTTPClient(proxy string) (*http.Client, error) {
+	uri, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	httpClient := http.Client{}
+	if c.HTTPClient != nil {
+		httpClient = *c.HTTPClient
+	}
+
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	base, ok := transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("ProxyProvider requires an *http.Transport, got %T", transport)
+	}
+
+	key := proxyKey{base: base, proxy: uri.String()}
+	cached, ok := proxyTransports.Load(key)
+	if !ok {
+		proxyTransport := base.Clone()
+		proxyTransport.Proxy = http.ProxyURL(uri)
+		cached, _ = proxyTransports.LoadOrStore(key, proxyTransport)
+	}
+
+	httpClient.Transport = cached.(*http.Transport)
+	return &httpClient, nil
+}
@@ -0,0 +1,124 @@
+package youtube
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ProxyProvider(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string][]string{}
+
+	// the proxies answer the requests themselves, each with its own stream URL
+	newProxy := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hits[name] = append(hits[name], r.URL.Host+r.URL.Path)
+			mu.Unlock()
+
+			if r.URL.Path == "/youtubei/v1/player" {
+				fmt.Fprintf(w, `{
+					"playabilityStatus": {"status": "OK", "playableInEmbed": true},
+					"streamingData": {"formats": [{"itag": 18, "url": "http://%s.video.test/18", "mimeType": "video/mp4", "contentLength": "10"}]}
+				}`, name)
+				return
+			}
+
+			start, _, _ := strings.Cut(r.URL.Query().Get("range"), "-")
+			offset, _ := strconv.Atoi(start)
+			io.WriteString(w, "0123456789"[offset:offset+2])
+		}))
+	}
+
+	proxyA := newProxy("a")
+	defer proxyA.Close()
+	proxyB := newProxy("b")
+	defer proxyB.Close()
+
+	proxies := []string{proxyA.URL, proxyB.URL}
+	next := 0
+
+	client := Client{
+		BaseURL:   "http://youtube.test",
+		ChunkSize: 2,
+		ProxyProvider: func() string {
+			proxy := proxies[next%len(proxies)]
+			next++
+			return proxy
+		},
+	}
+
+	videoA, err := client.GetVideo("BaW_jenozKc")
+	require.NoError(t, err)
+	videoB, err := client.GetVideo("jNQXAC9IVRw")
+	require.NoError(t, err)
+
+	// all chunks of a video go through the proxy which fetched it
+	for _, video := range []*Video{videoA, videoB} {
+		stream, _, err := client.GetStream(video, &video.Formats[0])
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(stream)
+		require.NoError(t, err)
+		assert.Equal(t, "0123456789", string(data))
+	}
+
+	assert.Equal(t, 2, next)
+	for _, name := range []string{"a", "b"} {
+		assert.Len(t, hits[name], 6)
+		assert.Equal(t, "youtube.test/youtubei/v1/player", hits[name][0])
+		for _, hit := range hits[name][1:] {
+			assert.Equal(t, name+".video.test/18", hit)
+		}
+	}
+
+	// the transport of a proxy is reused
+	assert.Same(t, videoA.httpClient.Transport, client.forVideo(videoA).HTTPClient.Transport)
+	httpClient, err := client.proxyHTTPClient(proxyA.URL)
+	require.NoError(t, err)
+	assert.Same(t, videoA.httpClient.Transport, httpClient.Transport)
+}
+
+func TestClient_IsAvailableUsesProxyProvider(t *testing.T) {
+	var hits []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, r.URL.Host+r.URL.Path)
+		io.WriteString(w, `{"playabilityStatus": {"status": "UNPLAYABLE", "reason": "The uploader has not made this video available in your country"}}`)
+	}))
+	defer proxy.Close()
+
+	client := Client{
+		BaseURL:       "http://youtube.test",
+		ProxyProvider: func() string { return proxy.URL },
+	}
+
+	available, err := client.IsAvailable("BaW_jenozKc")
+	assert.False(t, available)
+	assert.ErrorIs(t, err, ErrGeoRestricted)
+	assert.Equal(t, []string{"youtube.test/youtubei/v1/player"}, hits)
+}
+
+func TestClient_ProxyProviderRequiresTransport(t *testing.T) {
+	client := Client{
+		HTTPClient:    &http.Client{Transport: roundTripperFunc(nil)},
+		ProxyProvider: func() string { return "socks5://localhost:1080" },
+	}
+
+	_, err := client.GetVideo("BaW_jenozKc")
+	assert.ErrorContains(t, err, "ProxyProvider requires an *http.Transport")
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,91 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+var initialDataPattern = regexp.MustCompile(`var ytInitialData\s*=\s*(\{.+?\});`)
+
+// RelatedVideo is a video recommended next to another one on the video page
+type RelatedVideo struct {
+	ID     string
+	Title  string
+	Author string
+}
+
+// watchNextData is the part of ytInitialData of the video page containing the related videos
+type watchNextData struct {
+	Contents struct {
+		TwoColumnWatchNextResults struct {
+			SecondaryResults struct {
+				SecondaryResults struct {
+					Results []struct {
+						CompactVideoRenderer *struct {
+							VideoID string `json:"videoId"`
+							Title   struct {
+								SimpleText string `json:"simpleText"`
+							} `json:"title"`
+							LongBylineText withRuns `json:"longBylineText"`
+						} `json:"compactVideoRenderer"`
+					} `json:"results"`
+				} `json:"secondaryResults"`
+			} `json:"secondaryResults"`
+		} `json:"twoColumnWatchNextResults"`
+	} `json:"contents"`
+}
+
+// GetRelatedVideos fetches the videos recommended next to a video
+func (c *Client) GetRelatedVideos(url string) ([]RelatedVideo, error) {
+	return c.GetRelatedVideosContext(context.Background(), url)
+}
+
+// GetRelatedVideosContext fetches the videos recommended next to a video with a context.
+// The result is empty if the video page doesn't list any.
+func (c *Client) GetRelatedVideosContext(ctx context.Context, url string) ([]RelatedVideo, error) {
+	id, err := ExtractVideoID(url)
+	if err != nil {
+		return nil, fmt.Errorf("extractVideoID failed: %w", err)
+	}
+
+	c.assureClient()
+
+	body, err := c.httpGetBodyBytes(ctx, c.baseURL()+"/watch?v="+id)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRelatedVideos(body), nil
+}
+
+// parseRelatedVideos extracts the related videos from the ytInitialData of a video page
+func parseRelatedVideos(body []byte) []RelatedVideo {
+	initialData := initialDataPattern.FindSubmatch(body)
+	if initialData == nil {
+		return nil
+	}
+
+	var data watchNextData
+	if err := json.Unmarshal(initialData[1], &data); err != nil {
+		return nil
+	}
+
+	var videos []RelatedVideo
+	for _, result := range data.Contents.TwoColumnWatchNextResults.SecondaryResults.SecondaryResults.Results {
+		renderer := result.CompactVideoRenderer
+		if renderer == nil || renderer.VideoID == "" {
+			// playlists, mixes and other kinds of results
+			continue
+		}
+
+		videos = append(videos, RelatedVideo{
+			ID:     renderer.VideoID,
+			Title:  renderer.Title.SimpleText,
+			Author: renderer.LongBylineText.String(),
+		})
+	}
+
+	return videos
+}
@@ -0,0 +1,35 @@
+package youtube
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetRelatedVideos(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/watch", r.URL.Path)
+		assert.Equal(t, "BaW_jenozKc", r.URL.Query().Get("v"))
+
+		io.WriteString(w, `<script>var ytInitialData = {"contents": {"twoColumnWatchNextResults": {"secondaryResults": {"secondaryResults": {"results": [`+
+			`{"compactVideoRenderer": {"videoId": "jNQXAC9IVRw", "title": {"simpleText": "Me at the zoo"}, "longBylineText": {"runs": [{"text": "jawed"}]}}},`+
+			`{"compactPlaylistRenderer": {"playlistId": "PL59FEE129ADFF2B12"}}`+
+			`]}}}}};</script>`)
+	}))
+	defer server.Close()
+
+	client := Client{BaseURL: server.URL}
+
+	videos, err := client.GetRelatedVideos("https://www.youtube.com/watch?v=BaW_jenozKc")
+	require.NoError(t, err)
+	assert.Equal(t, []RelatedVideo{{ID: "jNQXAC9IVRw", Title: "Me at the zoo", Author: "jawed"}}, videos)
+}
+
+func TestParseRelatedVideos_Unavailable(t *testing.T) {
+	assert.Empty(t, parseRelatedVideos([]byte("<html></html>")))
+	assert.Empty(t, parseRelatedVideos([]byte(`var ytInitialData = {"contents": {}};`)))
+}
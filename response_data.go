@@ -1,5 +1,14 @@
 package youtube
 
+import (
+	"encoding/json"
+	"mime"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
 type playerResponseData struct {
 	Captions struct {
 		PlayerCaptionsTracklistRenderer struct {
@@ -54,6 +63,9 @@ type playerResponseData struct {
 		IsPrivate         bool    `json:"isPrivate"`
 		IsUnpluggedCorpus bool    `json:"isUnpluggedCorpus"`
 		IsLiveContent     bool    `json:"isLiveContent"`
+		IsLive            bool    `json:"isLive"`
+		IsLiveDvrEnabled  bool    `json:"isLiveDvrEnabled"`
+		LatencyClass      string  `json:"latencyClass"`
 	} `json:"videoDetails"`
 	Microformat struct {
 		PlayerMicroformatRenderer struct {
@@ -84,6 +96,11 @@ type playerResponseData struct {
 			UploadDate         string   `json:"uploadDate"`
 		} `json:"playerMicroformatRenderer"`
 	} `json:"microformat"`
+	Storyboards struct {
+		PlayerStoryboardSpecRenderer struct {
+			Spec string `json:"spec"`
+		} `json:"playerStoryboardSpecRenderer"`
+	} `json:"storyboards"`
 }
 
 type Format struct {
@@ -118,6 +135,13 @@ type Format struct {
 		End   string `json:"end"`
 	} `json:"indexRange"`
 
+	// ColorInfo is only available for video formats
+	ColorInfo *struct {
+		Primaries               string `json:"primaries"`
+		TransferCharacteristics string `json:"transferCharacteristics"`
+		MatrixCoefficients      string `json:"matrixCoefficients"`
+	} `json:"colorInfo"`
+
 	// AudioTrack is only available for videos with multiple audio track languages
 	AudioTrack *struct {
 		DisplayName    string `json:"displayName"`
@@ -133,6 +157,135 @@ func (f *Format) LanguageDisplayName() string {
 	return f.AudioTrack.DisplayName
 }
 
+// ByteOffset estimates the byte offset of a point in time within the stream.
+// It interpolates the content length if available, otherwise it uses the bitrate.
+func (f *Format) ByteOffset(t time.Duration) int64 {
+	if t <= 0 {
+		return 0
+	}
+
+	if ms, _ := strconv.ParseInt(f.ApproxDurationMs, 10, 64); ms > 0 && f.ContentLength > 0 {
+		offset := int64(float64(f.ContentLength) * float64(t.Milliseconds()) / float64(ms))
+		if offset > f.ContentLength {
+			return f.ContentLength
+		}
+		return offset
+	}
+
+	bitrate := f.AverageBitrate
+	if bitrate == 0 {
+		bitrate = f.Bitrate
+	}
+
+	return int64(t.Seconds() * float64(bitrate) / 8)
+}
+
+// FormatKind describes which streams a format contains.
+type FormatKind string
+
+const (
+	FormatProgressive FormatKind = "progressive" // muxed audio and video, up to 720p
+	FormatVideoOnly   FormatKind = "video-only"  // adaptive video without audio
+	FormatAudioOnly   FormatKind = "audio-only"  // adaptive audio without video
+)
+
+// Kind returns whether the format is a progressive or an adaptive (video-only or audio-only) stream.
+// Video formats listing an audio codec in their mime type are progressive,
+// the audio channels are only used if the mime type has no codecs.
+func (f *Format) Kind() FormatKind {
+	if strings.HasPrefix(f.MimeType, "audio/") {
+		return FormatAudioOnly
+	}
+
+	progressive := f.AudioChannels > 0
+	if _, params, err := mime.ParseMediaType(f.MimeType); err == nil && params["codecs"] != "" {
+		progressive = strings.Contains(params["codecs"], ",")
+	}
+
+	if progressive {
+		return FormatProgressive
+	}
+	return FormatVideoOnly
+}
+
+// UnmarshalJSON reads the signature cipher from "signatureCipher" or its former key "cipher"
+func (f *Format) UnmarshalJSON(data []byte) error {
+	type format Format
+	var raw struct {
+		format
+		LegacyCipher string `json:"cipher"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*f = Format(raw.format)
+	if f.Cipher == "" {
+		f.Cipher = raw.LegacyCipher
+	}
+
+	return nil
+}
+
+// Container returns the container of the stream from the mime type, e.g. "mp4" or "webm".
+func (f *Format) Container() string {
+	mediaType, _, _ := strings.Cut(f.MimeType, ";")
+	_, subtype, _ := strings.Cut(strings.TrimSpace(mediaType), "/")
+	return subtype
+}
+
+// IsCiphered returns whether the stream URL has to be deciphered from the signature cipher.
+// Formats with a URL only need the n parameter transformed, unless the Android client is used.
+func (f *Format) IsCiphered() bool {
+	return f.URL == "" && f.Cipher != ""
+}
+
+// IsHDR returns whether the format uses a high dynamic range transfer function (PQ or HLG).
+func (f *Format) IsHDR() bool {
+	if f.ColorInfo != nil {
+		switch f.ColorInfo.TransferCharacteristics {
+		case "COLOR_TRANSFER_CHARACTERISTICS_SMPTEST2084", "COLOR_TRANSFER_CHARACTERISTICS_ARIB_STD_B67":
+			return true
+		}
+	}
+
+	return strings.HasSuffix(f.QualityLabel, "HDR")
+}
+
+// Is360 returns whether the format is a 360° or VR video, which uses a spherical projection.
+func (f *Format) Is360() bool {
+	return f.ProjectionType != "" && f.ProjectionType != "RECTANGULAR"
+}
+
+// URLExpiresAt returns when the stream URL expires, as given by its expire parameter.
+// It is the zero time if the URL has no expiry. Expired URLs fail with 403, so the video has to be fetched again.
+func (f *Format) URLExpiresAt() time.Time {
+	streamURL := f.URL
+	if streamURL == "" {
+		params, _ := url.ParseQuery(f.Cipher)
+		streamURL = params.Get("url")
+	}
+
+	uri, err := url.Parse(streamURL)
+	if err != nil {
+		return time.Time{}
+	}
+
+	seconds, err := strconv.ParseInt(uri.Query().Get("expire"), 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(seconds, 0)
+}
+
+// IsURLExpired returns whether the stream URL has expired, false if its expiry is unknown.
+func (f *Format) IsURLExpired() bool {
+	expiresAt := f.URLExpiresAt()
+	return !expiresAt.IsZero() && time.Now().After(expiresAt)
+}
+
 type Thumbnails []Thumbnail
 
 type Thumbnail struct {
@@ -0,0 +1,85 @@
+package youtube
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat_ByteOffset(t *testing.T) {
+	t.Parallel()
+
+	withLength := Format{ContentLength: 1000, ApproxDurationMs: "10000", Bitrate: 8000}
+	assert.EqualValues(t, 0, withLength.ByteOffset(0))
+	assert.EqualValues(t, 250, withLength.ByteOffset(2500*time.Millisecond))
+	assert.EqualValues(t, 1000, withLength.ByteOffset(time.Minute))
+
+	withBitrate := Format{Bitrate: 8000, AverageBitrate: 4000}
+	assert.EqualValues(t, 1000, withBitrate.ByteOffset(2*time.Second))
+
+	withoutAverage := Format{Bitrate: 8000}
+	assert.EqualValues(t, 2000, withoutAverage.ByteOffset(2*time.Second))
+}
+
+func TestFormat_IsCiphered(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, (&Format{URL: "https://example.com/videoplayback"}).IsCiphered())
+	assert.True(t, (&Format{Cipher: "s=abc&sp=sig&url=https%3A%2F%2Fexample.com%2Fvideoplayback"}).IsCiphered())
+}
+
+func TestFormat_UnmarshalCipher(t *testing.T) {
+	t.Parallel()
+
+	for _, key := range []string{"signatureCipher", "cipher"} {
+		t.Run(key, func(t *testing.T) {
+			var format Format
+			err := json.Unmarshal([]byte(`{"itag": 18, "mimeType": "video/mp4", "`+key+`": "s=abc&sp=sig&url=https%3A%2F%2Fexample.com"}`), &format)
+			require.NoError(t, err)
+
+			assert.Equal(t, 18, format.ItagNo)
+			assert.Equal(t, "video/mp4", format.MimeType)
+			assert.Equal(t, "s=abc&sp=sig&url=https%3A%2F%2Fexample.com", format.Cipher)
+			assert.True(t, format.IsCiphered())
+		})
+	}
+}
+
+func TestFormat_HDRAnd360(t *testing.T) {
+	t.Parallel()
+
+	var hdr, vr Format
+	require.NoError(t, json.Unmarshal([]byte(`{"itag": 337, "qualityLabel": "2160p60 HDR", "projectionType": "RECTANGULAR",
+		"colorInfo": {"primaries": "COLOR_PRIMARIES_BT2020", "transferCharacteristics": "COLOR_TRANSFER_CHARACTERISTICS_SMPTEST2084"}}`), &hdr))
+	require.NoError(t, json.Unmarshal([]byte(`{"itag": 313, "qualityLabel": "2160p", "projectionType": "MESH"}`), &vr))
+
+	assert.True(t, hdr.IsHDR())
+	assert.False(t, hdr.Is360())
+	assert.False(t, vr.IsHDR())
+	assert.True(t, vr.Is360())
+
+	list := FormatList{hdr, vr}
+	assert.Equal(t, FormatList{vr}, list.HDR(false))
+	assert.Equal(t, FormatList{hdr}, list.Spherical(false))
+}
+
+func TestFormat_URLExpiresAt(t *testing.T) {
+	t.Parallel()
+
+	withURL := Format{URL: "https://example.com/videoplayback?expire=1700000000&itag=18"}
+	assert.Equal(t, time.Unix(1700000000, 0), withURL.URLExpiresAt())
+	assert.True(t, withURL.IsURLExpired())
+
+	future := time.Now().Add(time.Hour).Unix()
+	withCipher := Format{Cipher: "s=abc&sp=sig&url=https%3A%2F%2Fexample.com%2Fvideoplayback%3Fexpire%3D" + strconv.FormatInt(future, 10)}
+	assert.Equal(t, time.Unix(future, 0), withCipher.URLExpiresAt())
+	assert.False(t, withCipher.IsURLExpired())
+
+	withoutExpiry := Format{URL: "https://example.com/videoplayback"}
+	assert.True(t, withoutExpiry.URLExpiresAt().IsZero())
+	assert.False(t, withoutExpiry.IsURLExpired())
+}
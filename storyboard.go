@@ -0,0 +1,94 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrNoStoryboardSheet = errors.New("no storyboard sheet provided")
+
+// Storyboard is a level of sprite sheets with thumbnails of the video, as shown when scrubbing
+type Storyboard struct {
+	Width    int           // width of a single thumbnail
+	Height   int           // height of a single thumbnail
+	Count    int           // total number of thumbnails
+	Columns  int           // thumbnails per row of a sheet
+	Rows     int           // thumbnails per column of a sheet
+	Interval time.Duration // time between two thumbnails, 0 if unknown
+	URLs     []string      // URLs of the sprite sheets
+}
+
+// parseStoryboardSpec parses a spec like
+// https://i.ytimg.com/sb/ID/storyboard3_L$L/$N.jpg?sqp=...|48#27#100#10#10#0#default#rs$...|80#45#90#10#10#2000#M$M#rs$...
+// which consists of a URL template and one entry per level:
+// width#height#count#columns#rows#interval in ms#name of the sheets#signature
+func parseStoryboardSpec(spec string) []Storyboard {
+	parts := strings.Split(spec, "|")
+	if len(parts) < 2 {
+		return nil
+	}
+
+	template := parts[0]
+	var storyboards []Storyboard
+
+	for level, part := range parts[1:] {
+		fields := strings.Split(part, "#")
+		if len(fields) < 8 {
+			continue
+		}
+
+		values := make([]int, 6)
+		for i := range values {
+			values[i], _ = strconv.Atoi(fields[i])
+		}
+
+		sb := Storyboard{
+			Width:    values[0],
+			Height:   values[1],
+			Count:    values[2],
+			Columns:  values[3],
+			Rows:     values[4],
+			Interval: time.Duration(values[5]) * time.Millisecond,
+		}
+
+		if sb.Count <= 0 || sb.Columns <= 0 || sb.Rows <= 0 {
+			continue
+		}
+
+		baseURL := strings.ReplaceAll(template, "$L", strconv.Itoa(level))
+		separator := "?"
+		if strings.Contains(baseURL, "?") {
+			separator = "&"
+		}
+
+		perSheet := sb.Columns * sb.Rows
+		for sheet := 0; sheet < (sb.Count+perSheet-1)/perSheet; sheet++ {
+			name := strings.ReplaceAll(fields[6], "$M", strconv.Itoa(sheet))
+			sheetURL := strings.ReplaceAll(baseURL, "$N", name) + separator + "sigh=" + fields[7]
+			sb.URLs = append(sb.URLs, sheetURL)
+		}
+
+		storyboards = append(storyboards, sb)
+	}
+
+	return storyboards
+}
+
+// GetStoryboardSheet fetches the image of a sprite sheet
+func (c *Client) GetStoryboardSheet(storyboard *Storyboard, sheet int) ([]byte, error) {
+	return c.GetStoryboardSheetContext(context.Background(), storyboard, sheet)
+}
+
+// GetStoryboardSheetContext fetches the image of a sprite sheet with a context
+func (c *Client) GetStoryboardSheetContext(ctx context.Context, storyboard *Storyboard, sheet int) ([]byte, error) {
+	if storyboard == nil || sheet < 0 || sheet >= len(storyboard.URLs) {
+		return nil, ErrNoStoryboardSheet
+	}
+
+	c.assureClient()
+
+	return c.httpGetBodyBytes(ctx, storyboard.URLs[sheet])
+}
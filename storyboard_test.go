@@ -0,0 +1,53 @@
+package youtube
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStoryboardSpec(t *testing.T) {
+	spec := "https://i.ytimg.com/sb/ID/storyboard3_L$L/$N.jpg?sqp=abc|48#27#100#10#10#0#default#rs$first|80#45#120#10#10#2000#M$M#rs$second|invalid"
+
+	storyboards := parseStoryboardSpec(spec)
+	require.Len(t, storyboards, 2)
+
+	assert.Equal(t, Storyboard{
+		Width:   48,
+		Height:  27,
+		Count:   100,
+		Columns: 10,
+		Rows:    10,
+		URLs:    []string{"https://i.ytimg.com/sb/ID/storyboard3_L0/default.jpg?sqp=abc&sigh=rs$first"},
+	}, storyboards[0])
+
+	assert.Equal(t, 2*time.Second, storyboards[1].Interval)
+	assert.Equal(t, []string{
+		"https://i.ytimg.com/sb/ID/storyboard3_L1/M0.jpg?sqp=abc&sigh=rs$second",
+		"https://i.ytimg.com/sb/ID/storyboard3_L1/M1.jpg?sqp=abc&sigh=rs$second",
+	}, storyboards[1].URLs)
+
+	assert.Empty(t, parseStoryboardSpec(""))
+}
+
+func TestClient_GetStoryboardSheet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.URL.Path)
+	}))
+	defer server.Close()
+
+	client := Client{}
+	storyboard := &Storyboard{URLs: []string{server.URL + "/M0.jpg", server.URL + "/M1.jpg"}}
+
+	data, err := client.GetStoryboardSheet(storyboard, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "/M1.jpg", string(data))
+
+	_, err = client.GetStoryboardSheet(storyboard, 2)
+	assert.ErrorIs(t, err, ErrNoStoryboardSheet)
+}
@@ -0,0 +1,40 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNoThumbnail = errors.New("no thumbnail provided")
+
+// Largest returns the thumbnail with the highest resolution
+func (list Thumbnails) Largest() (Thumbnail, bool) {
+	if len(list) == 0 {
+		return Thumbnail{}, false
+	}
+
+	largest := list[0]
+	for _, t := range list[1:] {
+		if t.Width*t.Height > largest.Width*largest.Height {
+			largest = t
+		}
+	}
+
+	return largest, true
+}
+
+// GetThumbnail fetches the image of a thumbnail
+func (c *Client) GetThumbnail(thumbnail *Thumbnail) ([]byte, error) {
+	return c.GetThumbnailContext(context.Background(), thumbnail)
+}
+
+// GetThumbnailContext fetches the image of a thumbnail with a context
+func (c *Client) GetThumbnailContext(ctx context.Context, thumbnail *Thumbnail) ([]byte, error) {
+	if thumbnail == nil || thumbnail.URL == "" {
+		return nil, ErrNoThumbnail
+	}
+
+	c.assureClient()
+
+	return c.httpGetBodyBytes(ctx, thumbnail.URL)
+}
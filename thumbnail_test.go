@@ -0,0 +1,22 @@
+package youtube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThumbnails_Largest(t *testing.T) {
+	_, ok := Thumbnails{}.Largest()
+	assert.False(t, ok)
+
+	thumbnails := Thumbnails{
+		{URL: "default.jpg", Width: 120, Height: 90},
+		{URL: "maxresdefault.jpg", Width: 1280, Height: 720},
+		{URL: "hqdefault.jpg", Width: 480, Height: 360},
+	}
+
+	largest, ok := thumbnails.Largest()
+	assert.True(t, ok)
+	assert.Equal(t, "maxresdefault.jpg", largest.URL)
+}
@@ -13,9 +13,14 @@ type chunk struct {
 }
 
 func getChunks(totalSize, chunkSize int64) []chunk {
+	return getChunksInRange(0, totalSize, chunkSize)
+}
+
+// getChunksInRange splits the bytes from offset (inclusive) to totalSize (exclusive) into chunks
+func getChunksInRange(offset, totalSize, chunkSize int64) []chunk {
 	var chunks []chunk
 
-	for start := int64(0); start < totalSize; start += chunkSize {
+	for start := offset; start < totalSize; start += chunkSize {
 		end := chunkSize + start - 1
 		if end > totalSize-1 {
 			end = totalSize - 1
@@ -25,3 +25,16 @@ func TestGetChunks_length(t *testing.T) {
 	require.Len(getChunks(10, 10), 1)
 	require.Len(getChunks(10, 11), 1)
 }
+
+func TestGetChunksInRange(t *testing.T) {
+	require := require.New(t)
+	chunks := getChunksInRange(7, 13, 5)
+
+	require.Len(chunks, 2)
+	require.EqualValues(7, chunks[0].start)
+	require.EqualValues(11, chunks[0].end)
+	require.EqualValues(12, chunks[1].start)
+	require.EqualValues(12, chunks[1].end)
+
+	require.Empty(getChunksInRange(13, 13, 5))
+}
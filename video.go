@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"regexp"
 	"sort"
@@ -20,13 +21,28 @@ type Video struct {
 	ChannelID       string
 	ChannelHandle   string
 	Views           int
+	IsLive          bool
+	IsLiveDVR       bool   // whether the live stream can be recorded from the past, within the window of the HLS playlist
+	LiveLatency     string // latency class of the live stream, e.g. NORMAL, LOW or ULTRALOW
+	Keywords        []string
 	Duration        time.Duration
 	PublishDate     time.Time
+	UploadDate      time.Time
+	Category        string // e.g. Music or Education
+	IsFamilySafe    bool
+	IsUnlisted      bool
+	Countries       []string // ISO 3166 codes of the countries the video is available in, empty if unknown
 	Formats         FormatList
 	Thumbnails      Thumbnails
 	DASHManifestURL string // URI of the DASH manifest file
 	HLSManifestURL  string // URI of the HLS manifest file
 	CaptionTracks   []CaptionTrack
+	Storyboards     []Storyboard // sprite sheets of thumbnails, ordered from lowest to highest resolution
+	Chapters        Chapters     // chapters listed in the description, empty if there are none
+
+	playerResponse json.RawMessage
+	client         *clientInfo  // innertube client which fetched the video, its streams are requested the same way
+	httpClient     *http.Client // HTTP client of the proxy of ProxyProvider which fetched the video
 }
 
 const dateFormat = "2006-01-02"
@@ -41,9 +57,17 @@ func (v *Video) parseVideoInfo(body []byte) error {
 		return err
 	}
 
+	v.playerResponse = body
+
 	return v.extractDataFromPlayerResponse(prData)
 }
 
+// PlayerResponse returns the raw JSON of the player response,
+// e.g. to read the microformat, storyboards or other fields which are not part of Video.
+func (v *Video) PlayerResponse() json.RawMessage {
+	return v.playerResponse
+}
+
 func (v *Video) isVideoFromInfoDownloadable(prData playerResponseData) error {
 	return v.isVideoDownloadable(prData, false)
 }
@@ -65,6 +89,8 @@ func (v *Video) parseVideoPage(body []byte) error {
 		return err
 	}
 
+	v.playerResponse = initialPlayerResponse[1]
+
 	return v.extractDataFromPlayerResponse(prData)
 }
 
@@ -101,6 +127,11 @@ func (v *Video) extractDataFromPlayerResponse(prData playerResponseData) error {
 	v.Author = prData.VideoDetails.Author
 	v.Thumbnails = prData.VideoDetails.Thumbnail.Thumbnails
 	v.ChannelID = prData.VideoDetails.ChannelID
+	v.Keywords = prData.VideoDetails.Keywords
+	v.IsLive = prData.VideoDetails.IsLive
+	v.IsLiveDVR = prData.VideoDetails.IsLiveDvrEnabled
+	v.LiveLatency = strings.TrimPrefix(prData.VideoDetails.LatencyClass, "MDE_STREAM_OPTIMIZATIONS_RENDERER_LATENCY_")
+	v.Storyboards = parseStoryboardSpec(prData.Storyboards.PlayerStoryboardSpecRenderer.Spec)
 	v.CaptionTracks = prData.Captions.PlayerCaptionsTracklistRenderer.CaptionTracks
 
 	if views, _ := strconv.Atoi(prData.VideoDetails.ViewCount); views > 0 {
@@ -115,29 +146,46 @@ func (v *Video) extractDataFromPlayerResponse(prData playerResponseData) error {
 		v.Duration = time.Duration(seconds) * time.Second
 	}
 
-	if str := prData.Microformat.PlayerMicroformatRenderer.PublishDate; str != "" {
-		v.PublishDate, _ = time.Parse(dateFormat, str)
-	}
+	v.Chapters = parseChapters(v.Description, v.Duration)
+
+	microformat := prData.Microformat.PlayerMicroformatRenderer
+	v.PublishDate = parseMicroformatDate(microformat.PublishDate)
+	v.UploadDate = parseMicroformatDate(microformat.UploadDate)
+	v.Category = microformat.Category
+	v.IsFamilySafe = microformat.IsFamilySafe
+	v.IsUnlisted = microformat.IsUnlisted
+	v.Countries = microformat.AvailableCountries
 
 	if profileURL, err := url.Parse(prData.Microformat.PlayerMicroformatRenderer.OwnerProfileURL); err == nil && len(profileURL.Path) > 1 {
 		v.ChannelHandle = profileURL.Path[1:]
 	}
 
+	v.HLSManifestURL = prData.StreamingData.HlsManifestURL
+	v.DASHManifestURL = prData.StreamingData.DashManifestURL
+
 	// Assign Streams
 	v.Formats = append(prData.StreamingData.Formats, prData.StreamingData.AdaptiveFormats...)
-	if len(v.Formats) == 0 {
-		return errors.New("no formats found in the server's answer")
+	if len(v.Formats) == 0 && v.HLSManifestURL == "" && v.DASHManifestURL == "" {
+		// live streams may only provide manifests
+		return ErrNoFormats
 	}
 
 	// Sort formats by bitrate
 	sort.SliceStable(v.Formats, v.SortBitrateDesc)
 
-	v.HLSManifestURL = prData.StreamingData.HlsManifestURL
-	v.DASHManifestURL = prData.StreamingData.DashManifestURL
-
 	return nil
 }
 
+// parseMicroformatDate parses dates like 2006-01-02 or 2006-01-02T15:04:05-07:00, the zero time if empty or invalid
+func parseMicroformatDate(str string) time.Time {
+	if t, err := time.Parse(time.RFC3339, str); err == nil {
+		return t
+	}
+
+	t, _ := time.Parse(dateFormat, str)
+	return t
+}
+
 func (v *Video) SortBitrateDesc(i int, j int) bool {
 	return v.Formats[i].Bitrate > v.Formats[j].Bitrate
 }
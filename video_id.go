@@ -1,10 +1,15 @@
 package youtube
 
 import (
+	"net/url"
 	"regexp"
 	"strings"
 )
 
+const videoIDLength = 11
+
+var videoIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
 var videoRegexpList = []*regexp.Regexp{
 	regexp.MustCompile(`(?:v|embed|shorts|watch\?v)(?:=|/)([^"&?/=%]{11})`),
 	regexp.MustCompile(`(?:=|/)([^"&?/=%]{11})`),
@@ -14,10 +19,14 @@ var videoRegexpList = []*regexp.Regexp{
 // ExtractVideoID extracts the videoID from the given string
 func ExtractVideoID(videoID string) (string, error) {
 	if strings.Contains(videoID, "youtu") || strings.ContainsAny(videoID, "\"?&/<%=") {
-		for _, re := range videoRegexpList {
-			if isMatch := re.MatchString(videoID); isMatch {
-				subs := re.FindStringSubmatch(videoID)
-				videoID = subs[1]
+		if id, ok := videoIDFromURL(videoID); ok {
+			videoID = id
+		} else {
+			for _, re := range videoRegexpList {
+				if isMatch := re.MatchString(videoID); isMatch {
+					subs := re.FindStringSubmatch(videoID)
+					videoID = subs[1]
+				}
 			}
 		}
 	}
@@ -26,9 +35,62 @@ func ExtractVideoID(videoID string) (string, error) {
 		return "", ErrInvalidCharactersInVideoID
 	}
 
-	if len(videoID) < 10 {
+	if len(videoID) < videoIDLength {
 		return "", ErrVideoIDMinLength
 	}
 
+	if len(videoID) > videoIDLength {
+		return "", ErrVideoIDMaxLength
+	}
+
+	if !videoIDPattern.MatchString(videoID) {
+		return "", ErrInvalidCharactersInVideoID
+	}
+
 	return videoID, nil
 }
+
+// videoIDFromURL extracts the videoID from the well-known URL shapes:
+// watch?v=, youtu.be/, /shorts/, /live/, /embed/ and /v/
+func videoIDFromURL(rawURL string) (string, bool) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	uri, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+
+	host := strings.ToLower(uri.Hostname())
+	segments := strings.Split(strings.Trim(uri.Path, "/"), "/")
+
+	switch {
+	case host == "youtu.be" || strings.HasSuffix(host, ".youtu.be"):
+		return segments[0], segments[0] != ""
+
+	case isYoutubeHost(host):
+		if id := uri.Query().Get("v"); id != "" {
+			return id, true
+		}
+
+		if len(segments) >= 2 {
+			switch segments[0] {
+			case "shorts", "live", "embed", "v", "e":
+				return segments[1], segments[1] != ""
+			}
+		}
+	}
+
+	return "", false
+}
+
+func isYoutubeHost(host string) bool {
+	for _, domain := range []string{"youtube.com", "youtube-nocookie.com"} {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
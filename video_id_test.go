@@ -0,0 +1,63 @@
+package youtube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractVideoID_URLShapes(t *testing.T) {
+	const id = "rFejpH_tAHM"
+
+	urls := []string{
+		"https://www.youtube.com/watch?v=rFejpH_tAHM",
+		"https://m.youtube.com/watch?v=rFejpH_tAHM&feature=youtu.be",
+		"https://www.youtube.com/watch?feature=share&v=rFejpH_tAHM",
+		"https://www.youtube.com/watch?v=rFejpH_tAHM&list=PLqAfPOrmacr963ATEroh67fbvjmTzTEx5&index=2",
+		"https://music.youtube.com/watch?v=rFejpH_tAHM&si=abcdefghijklmnop",
+		"youtube.com/watch?v=rFejpH_tAHM",
+		"https://youtu.be/rFejpH_tAHM",
+		"https://youtu.be/rFejpH_tAHM?t=42&si=abcdefghijklmnop",
+		"https://www.youtube.com/shorts/rFejpH_tAHM",
+		"https://youtube.com/shorts/rFejpH_tAHM?feature=share",
+		"www.youtube.com/shorts/rFejpH_tAHM",
+		"https://www.youtube.com/live/rFejpH_tAHM?si=abcdefghijklmnop",
+		"https://www.youtube.com/embed/rFejpH_tAHM",
+		"https://www.youtube-nocookie.com/embed/rFejpH_tAHM?start=10",
+		"https://www.youtube.com/v/rFejpH_tAHM?version=3",
+		"rFejpH_tAHM",
+	}
+
+	for _, u := range urls {
+		t.Run(u, func(t *testing.T) {
+			videoID, err := ExtractVideoID(u)
+			if assert.NoError(t, err) {
+				assert.Equal(t, id, videoID)
+			}
+		})
+	}
+}
+
+func TestExtractVideoID_Length(t *testing.T) {
+	tests := []struct {
+		videoID     string
+		expectedErr error
+	}{
+		{"rFejpH_tAH", ErrVideoIDMinLength},
+		{"rFejpH_tAHM", nil},
+		{"rFejpH_tAHMx", ErrVideoIDMaxLength},
+		{"rFejpH tAHM", ErrInvalidCharactersInVideoID},
+		{"rFejpH.tAHM", ErrInvalidCharactersInVideoID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.videoID, func(t *testing.T) {
+			videoID, err := ExtractVideoID(tt.videoID)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tt.videoID, videoID)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -155,3 +156,93 @@ func TestDownload_SensitiveContent(t *testing.T) {
 	_, err := testClient.GetVideo("MS91knuzoOA")
 	require.EqualError(t, err, "can't bypass age restriction: embedding of this video has been disabled")
 }
+
+func TestParseVideoInfo(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	body := []byte(`{
+		"playabilityStatus": {"status": "OK", "playableInEmbed": true},
+		"streamingData": {
+			"formats": [{"itag": 18, "url": "https://example.com/18", "mimeType": "video/mp4", "bitrate": 500}]
+		},
+		"videoDetails": {
+			"videoId": "BaW_jenozKc",
+			"title": "youtube-dl test video",
+			"lengthSeconds": "10",
+			"keywords": ["youtube-dl", "test video"],
+			"channelId": "UCLqxVugv74EIW3VWh2NOa3Q",
+			"shortDescription": "test chars",
+			"viewCount": "1234",
+			"author": "Philipp Hagemeister"
+		}
+	}`)
+
+	v := Video{ID: "BaW_jenozKc"}
+	require.NoError(v.parseVideoInfo(body))
+
+	assert.Equal("youtube-dl test video", v.Title)
+	assert.Equal("Philipp Hagemeister", v.Author)
+	assert.Equal("UCLqxVugv74EIW3VWh2NOa3Q", v.ChannelID)
+	assert.Equal("test chars", v.Description)
+	assert.Equal(10*time.Second, v.Duration)
+	assert.Equal(1234, v.Views)
+	assert.Equal([]string{"youtube-dl", "test video"}, v.Keywords)
+	require.Len(v.Formats, 1)
+	assert.Equal(18, v.Formats[0].ItagNo)
+	assert.JSONEq(string(body), string(v.PlayerResponse()))
+}
+
+func TestParseVideoInfo_Live(t *testing.T) {
+	body := []byte(`{
+		"playabilityStatus": {"status": "OK", "playableInEmbed": true},
+		"streamingData": {"hlsManifestUrl": "https://example.com/index.m3u8"},
+		"videoDetails": {
+			"videoId": "jfKfPfyJRdk",
+			"isLive": true,
+			"isLiveDvrEnabled": true,
+			"latencyClass": "MDE_STREAM_OPTIMIZATIONS_RENDERER_LATENCY_LOW"
+		}
+	}`)
+
+	v := Video{ID: "jfKfPfyJRdk"}
+	require.NoError(t, v.parseVideoInfo(body))
+
+	assert.True(t, v.IsLive)
+	assert.True(t, v.IsLiveDVR)
+	assert.Equal(t, "LOW", v.LiveLatency)
+	assert.Equal(t, "https://example.com/index.m3u8", v.HLSManifestURL)
+}
+
+func TestParseVideoInfo_Microformat(t *testing.T) {
+	body := []byte(`{
+		"playabilityStatus": {"status": "OK"},
+		"streamingData": {"formats": [{"itag": 18, "url": "https://example.com/18"}]},
+		"microformat": {"playerMicroformatRenderer": {
+			"publishDate": "2012-10-02",
+			"uploadDate": "2012-10-01T15:27:35-07:00",
+			"category": "Science & Technology",
+			"isFamilySafe": true,
+			"isUnlisted": false,
+			"availableCountries": ["DE", "US"]
+		}}
+	}`)
+
+	v := Video{ID: "BaW_jenozKc"}
+	require.NoError(t, v.parseVideoInfo(body))
+
+	assert.Equal(t, time.Date(2012, 10, 2, 0, 0, 0, 0, time.UTC), v.PublishDate)
+	assert.True(t, time.Date(2012, 10, 1, 22, 27, 35, 0, time.UTC).Equal(v.UploadDate))
+	assert.Equal(t, "Science & Technology", v.Category)
+	assert.True(t, v.IsFamilySafe)
+	assert.False(t, v.IsUnlisted)
+	assert.Equal(t, []string{"DE", "US"}, v.Countries)
+}
+
+func TestParseVideoInfo_PlayabilityStatus(t *testing.T) {
+	body := []byte(`{"playabilityStatus": {"status": "CONTENT_CHECK_REQUIRED", "reason": "This video may be inappropriate for some users.", "playableInEmbed": true}}`)
+
+	v := Video{ID: "BaW_jenozKc"}
+	err := v.parseVideoInfo(body)
+	require.ErrorIs(t, err, ErrContentCheckRequired)
+	require.Contains(t, err.Error(), "This video may be inappropriate for some users.")
+}